@@ -18,11 +18,13 @@ const (
 	MULTIPLY_ASSIGNMENT
 	DIVIDE_ASSIGNMENT
 	MODULO_ASSIGNMENT
+	POWER_ASSIGNMENT
 	BITWISE_AND_ASSIGNMENT
 	BITWISE_OR_ASSIGNMENT
 	BITWISE_XOR_ASSIGNMENT
 	BITWISE_LEFT_SHIFT_ASSIGNMENT
 	BITWISE_RIGHT_SHIFT_ASSIGNMENT
+	OPTIONAL_ASSIGNMENT // `x ?= 1` assigns only when x is none
 )
 
 type AssignmentExpr struct {
@@ -30,11 +32,11 @@ type AssignmentExpr struct {
 	source utils.String
 	Const  bool
 	Name   string
+	Type   env.Type // annotation of a declaration, `s : str = 'Fizz'`, nil when inferred
 	Value  Expr
 }
 
-func NewAssignmentExpr(identifier lexer.Token, assignmentToken lexer.Token, value Expr) AssignmentExpr {
-	fmt.Println("Assigning!", identifier.Value.String(), assignmentToken.Value.String(), value.Source())
+func assignmentKind(assignmentToken lexer.Token) AssignmentKind {
 	kind := ASSIGNMENT
 	switch assignmentToken.Type {
 	case lexer.COLON_ASSIGN:
@@ -51,6 +53,8 @@ func NewAssignmentExpr(identifier lexer.Token, assignmentToken lexer.Token, valu
 		kind = DIVIDE_ASSIGNMENT
 	case lexer.MODULO_ASSIGN:
 		kind = MODULO_ASSIGNMENT
+	case lexer.POWER_ASSIGN:
+		kind = POWER_ASSIGNMENT
 	case lexer.BITWISE_AND_ASSIGN:
 		kind = BITWISE_AND_ASSIGNMENT
 	case lexer.BITWISE_OR_ASSIGN:
@@ -61,99 +65,288 @@ func NewAssignmentExpr(identifier lexer.Token, assignmentToken lexer.Token, valu
 		kind = BITWISE_LEFT_SHIFT_ASSIGNMENT
 	case lexer.BITWISE_RIGHT_SHIFT_ASSIGN:
 		kind = BITWISE_RIGHT_SHIFT_ASSIGNMENT
+	case lexer.OPTIONAL_ASSIGN:
+		kind = OPTIONAL_ASSIGNMENT
 	}
+	return kind
+}
+
+func NewAssignmentExpr(identifier lexer.Token, assignmentToken lexer.Token, value Expr) AssignmentExpr {
 	return AssignmentExpr{
-		Const:  false, // TODO: should be true by default but set to false for testing
-		Kind:   kind,
+		Const:  true,
+		Kind:   assignmentKind(assignmentToken),
 		source: identifier.Value,
 		Name:   identifier.Value.String(),
 		Value:  value,
 	}
 }
 
-func (a AssignmentExpr) Eval(env *env.Env) (env.Value, error) {
-	value, err := a.Value.Eval(env)
-	if err != nil {
-		return nil, err
+// NewDeclarationExpr creates an explicit `let` or `mut` declaration,
+// only `mut` declarations can be assigned to afterwards
+func NewDeclarationExpr(keyword lexer.Token, identifier lexer.Token, value Expr) AssignmentExpr {
+	return AssignmentExpr{
+		Const:  keyword.Type != lexer.MUT,
+		Kind:   DECLARATION,
+		source: utils.Encompass(keyword.Value, identifier.Value),
+		Name:   identifier.Value.String(),
+		Value:  value,
 	}
-	oldValue, ok := env.Get(a.Name)
-	switch a.Kind {
-	case ASSIGNMENT:
-		if ok {
-			if !a.Const {
-				env.Set(a.Name, value, a.Const)
-			} else {
-				return nil, utils.Error{Source: a.Source(), Message: "cannot assign to constant"}
-			}
-		} else {
-			env.Set(a.Name, value, a.Const)
-		}
-	case DECLARATION:
-		env.Set(a.Name, value, a.Const)
-	case PLUS_ASSIGNMENT:
-		newValue, err := AddValues(value, oldValue, a.Source())
+}
+
+// WithType turns the assignment into a declaration annotated with t
+func (a AssignmentExpr) WithType(t env.Type) AssignmentExpr {
+	a.Kind = DECLARATION
+	a.Type = t
+	return a
+}
+
+func (a AssignmentExpr) Eval(e *env.Env) (env.Value, error) {
+	if a.Kind == DECLARATION {
+		t := resolveTypeArgs(e, a.Type)
+		value, err := evalAssigned(e, a.Value, t)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case MINUS_ASSIGNMENT:
-		newValue, err := SubtractValues(value, oldValue, a.Source())
-		if err != nil {
-			return nil, err
+		if t != nil && !env.SameType(value.Type(), t) {
+			return nil, utils.Error{Source: a.Value.Source(), Message: fmt.Sprintf("expected %s for %s, got %s", t.Name(), a.Name, value.Type().Name())}
 		}
-		env.Set(a.Name, newValue, a.Const)
+		value = env.Copy(value)
+		e.Declare(a.Name, value, t, a.Const)
+		return value, nil
+	}
+
+	// every other kind of assignment writes to an existing variable
+	old, ok := e.Lookup(a.Name)
+	if !ok {
+		return nil, utils.Error{Source: a.Source(), Message: "variable not found"}
+	}
+	if old.Const {
+		return nil, utils.Error{Source: a.Source(), Message: "cannot assign to immutable variable " + a.Name + ", declare it with mut"}
+	}
+
+	if skipsAssignment(a.Kind, old.Value) {
+		return old.Value, nil
+	}
+
+	// variables keep their type, literals assigned to them are given it
+	value, err := evalAssigned(e, a.Value, assignedType(a.Kind, old.Value, old.Type))
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := applyAssignment(a.Kind, old.Value, value, e.Overflow(), a.Source())
+	if err != nil {
+		return nil, err
+	}
+	if !env.SameType(newValue.Type(), old.Type) {
+		return nil, utils.Error{Source: a.Value.Source(), Message: fmt.Sprintf("cannot assign %s to %s of type %s", newValue.Type().Name(), a.Name, old.Type.Name())}
+	}
+	newValue = env.Copy(newValue)
+	e.Assign(a.Name, newValue)
+	return newValue, nil
+}
+
+// evalAssigned evaluates the value stored in a place of type t, see evalAs
+func evalAssigned(e *env.Env, expr Expr, t env.Type) (env.Value, error) {
+	value, err := evalAs(e, expr, t)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, utils.Error{Source: expr.Source(), Message: "expression has no value"}
+	}
+	return value, nil
+}
+
+// CompoundOperators maps compound assignments to the operator they apply, `x += 1` is `x = x + 1`
+var CompoundOperators = map[AssignmentKind]lexer.TokenType{
+	PLUS_ASSIGNMENT:                lexer.PLUS,
+	MINUS_ASSIGNMENT:               lexer.MINUS,
+	MULTIPLY_ASSIGNMENT:            lexer.MULTIPLY,
+	DIVIDE_ASSIGNMENT:              lexer.DIVIDE,
+	MODULO_ASSIGNMENT:              lexer.MODULO,
+	POWER_ASSIGNMENT:               lexer.POWER,
+	BITWISE_AND_ASSIGNMENT:         lexer.BITWISE_AND,
+	BITWISE_OR_ASSIGNMENT:          lexer.BITWISE_OR,
+	BITWISE_XOR_ASSIGNMENT:         lexer.BITWISE_XOR,
+	BITWISE_LEFT_SHIFT_ASSIGNMENT:  lexer.BITWISE_LEFT_SHIFT,
+	BITWISE_RIGHT_SHIFT_ASSIGNMENT: lexer.BITWISE_RIGHT_SHIFT,
+}
+
+// assignedType is the type a literal assigned to a place of type t holding old takes, `x <<= 2`
+// shifts by a u32
+func assignedType(kind AssignmentKind, old env.Value, t env.Type) env.Type {
+	if op, ok := CompoundOperators[kind]; ok {
+		return OperandType(op, old)
+	}
+	return t
+}
+
+// skipsAssignment reports whether an assignment leaves a place holding old untouched, without
+// evaluating its value: `x ?= f()` only calls f when x is none
+func skipsAssignment(kind AssignmentKind, old env.Value) bool {
+	return kind == OPTIONAL_ASSIGNMENT && !env.IsNone(old)
+}
+
+// applyAssignment computes the value stored by an assignment of the given kind
+func applyAssignment(kind AssignmentKind, old, value env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	switch kind {
+	case PLUS_ASSIGNMENT:
+		return AddValues(old, value, overflow, source)
+	case MINUS_ASSIGNMENT:
+		return SubtractValues(old, value, overflow, source)
 	case MULTIPLY_ASSIGNMENT:
-		newValue, err := MultiplyValues(value, oldValue, a.Source())
+		return MultiplyValues(old, value, overflow, source)
+	case DIVIDE_ASSIGNMENT:
+		return DivideValues(old, value, overflow, source)
+	case MODULO_ASSIGNMENT:
+		return ModuloValues(old, value, overflow, source)
+	case POWER_ASSIGNMENT:
+		return PowerValues(old, value, overflow, source)
+	case BITWISE_AND_ASSIGNMENT:
+		return BitwiseAndValues(old, value, source)
+	case BITWISE_OR_ASSIGNMENT:
+		return BitwiseOrValues(old, value, source)
+	case BITWISE_XOR_ASSIGNMENT:
+		return BitwiseXorValues(old, value, source)
+	case BITWISE_LEFT_SHIFT_ASSIGNMENT:
+		return BitwiseLeftShiftValues(old, value, source)
+	case BITWISE_RIGHT_SHIFT_ASSIGNMENT:
+		return BitwiseRightShiftValues(old, value, source)
+	}
+	return value, nil
+}
+
+func (a AssignmentExpr) Source() utils.String {
+	return a.source
+}
+
+// PlaceAssignmentExpr assigns to a comp field or a list element, `me.age += 1`, `xs[0] = 2`.
+// The variable holding the comp or list has to be mutable.
+type PlaceAssignmentExpr struct {
+	source utils.String
+	Kind   AssignmentKind
+	Target Expr // a FieldExpr or an IndexExpr
+	Value  Expr
+}
+
+func NewPlaceAssignmentExpr(target Expr, assignmentToken lexer.Token, value Expr) PlaceAssignmentExpr {
+	return PlaceAssignmentExpr{
+		source: target.Source(),
+		Kind:   assignmentKind(assignmentToken),
+		Target: target,
+		Value:  value,
+	}
+}
+
+func (a PlaceAssignmentExpr) Source() utils.String {
+	return a.source
+}
+
+func (a PlaceAssignmentExpr) Eval(e *env.Env) (env.Value, error) {
+	if err := checkMutableRoot(e, a.Target, "assign through"); err != nil {
+		return nil, err
+	}
+	switch target := a.Target.(type) {
+	case FieldExpr:
+		container, err := target.Target.Eval(e)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case DIVIDE_ASSIGNMENT:
-		newValue, err := DivideValues(value, oldValue, a.Source())
+		s, ok := container.(*env.StructValue)
+		if !ok {
+			return nil, utils.Error{Source: a.source, Message: fmt.Sprintf("cannot assign to a field of %s", typeNameOf(container))}
+		}
+		i := s.StructType.FieldIndex(target.Field)
+		if i < 0 {
+			return nil, utils.Error{Source: a.source, Message: fmt.Sprintf("%s has no field %s", s.StructType.Name(), target.Field)}
+		}
+		field := s.StructType.Fields[i]
+		if skipsAssignment(a.Kind, s.Fields[i]) {
+			return s.Fields[i], nil
+		}
+		value, err := evalAssigned(e, a.Value, assignedType(a.Kind, s.Fields[i], field.Type))
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case MODULO_ASSIGNMENT:
-		newValue, err := ModuloValues(value, oldValue, a.Source())
+		newValue, err := applyAssignment(a.Kind, s.Fields[i], value, e.Overflow(), a.source)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case BITWISE_AND_ASSIGNMENT:
-		newValue, err := BitwiseAndValues(value, oldValue, a.Source())
+		if !env.SameType(newValue.Type(), field.Type) {
+			return nil, utils.Error{Source: a.Value.Source(), Message: fmt.Sprintf("field %s expects %s, got %s", field.Name, field.Type.Name(), newValue.Type().Name())}
+		}
+		s.Fields[i] = env.Copy(newValue)
+		return s.Fields[i], nil
+	case IndexExpr:
+		container, err := target.Target.Eval(e)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case BITWISE_OR_ASSIGNMENT:
-		newValue, err := BitwiseOrValues(value, oldValue, a.Source())
+		list, ok := container.(*env.ListValue)
+		if !ok {
+			return nil, utils.Error{Source: a.source, Message: fmt.Sprintf("cannot assign to an element of %s", typeNameOf(container))}
+		}
+		index, err := target.Index.Eval(e)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case BITWISE_XOR_ASSIGNMENT:
-		newValue, err := BitwiseXorValues(value, oldValue, a.Source())
+		i, err := indexBounds(index, list.Len(), target.Index.Source())
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case BITWISE_LEFT_SHIFT_ASSIGNMENT:
-		newValue, err := BitwiseLeftShiftValues(value, oldValue, a.Source())
+		if skipsAssignment(a.Kind, list.Items[i]) {
+			return list.Items[i], nil
+		}
+		value, err := evalAssigned(e, a.Value, assignedType(a.Kind, list.Items[i], list.Elem))
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
-	case BITWISE_RIGHT_SHIFT_ASSIGNMENT:
-		newValue, err := BitwiseRightShiftValues(value, oldValue, a.Source())
+		newValue, err := applyAssignment(a.Kind, list.Items[i], value, e.Overflow(), a.source)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(a.Name, newValue, a.Const)
+		if !env.SameType(newValue.Type(), list.Elem) {
+			return nil, utils.Error{Source: a.Value.Source(), Message: fmt.Sprintf("cannot store %s in %s", newValue.Type().Name(), list.Type().Name())}
+		}
+		list.Items[i] = env.Copy(newValue)
+		return list.Items[i], nil
 	}
-	return value, nil
+	return nil, utils.Error{Source: a.source, Message: "cannot assign to this expression"}
 }
 
-func (a AssignmentExpr) Source() utils.String {
-	return a.source
+// checkMutableRoot makes sure the variable a place expression starts from is mutable, action is
+// what the error says cannot be done through it
+func checkMutableRoot(e *env.Env, place Expr, action string) error {
+	id, ok := placeRoot(place)
+	if !ok {
+		// temporaries, e.g. `make().x = 1`
+		return nil
+	}
+	v, ok := e.Lookup(id.Name())
+	if !ok {
+		return utils.Error{Source: id.Source(), Message: "variable not found"}
+	}
+	if v.Const {
+		return utils.Error{Source: id.Source(), Message: "cannot " + action + " immutable variable " + id.Name() + ", declare it with mut"}
+	}
+	return nil
+}
+
+// placeRoot returns the variable a place expression such as `p.items[0]` starts from, ok is false
+// when it starts from a temporary
+func placeRoot(place Expr) (Identifier, bool) {
+	for {
+		switch p := place.(type) {
+		case FieldExpr:
+			place = p.Target
+		case IndexExpr:
+			place = p.Target
+		case ParenExpr:
+			place = p.Expr
+		case Identifier:
+			return p, true
+		default:
+			return Identifier{}, false
+		}
+	}
 }
@@ -26,12 +26,24 @@ type Scope struct {
 	Exprs []Expr
 }
 
+// Eval runs the scope's expressions in a new child of env
 func (s *Scope) Eval(env *env.Env) (env.Value, error) {
+	return s.EvalIn(env.NewChild())
+}
+
+// EvalIn runs the scope's expressions directly in env, which is how
+// a program's top level shares its declarations with the caller
+func (s *Scope) EvalIn(env *env.Env) (env.Value, error) {
+	return s.evalInAs(env, nil)
+}
+
+// evalInAs runs the scope in env, its last expression is evaluated where a value of type t is expected
+func (s *Scope) evalInAs(e *env.Env, t env.Type) (env.Value, error) {
 	for i, expr := range s.Exprs {
 		if i == len(s.Exprs)-1 {
-			return expr.Eval(env)
+			return evalAs(e, expr, t)
 		}
-		_, err := expr.Eval(env)
+		_, err := expr.Eval(e)
 		if err != nil {
 			return nil, err
 		}
@@ -1,11 +1,31 @@
 package ast
 
 import (
+	"fmt"
+	"math"
+	"math/big"
+
 	"com.loop.anonx3247/env"
 	"com.loop.anonx3247/lexer"
 	"com.loop.anonx3247/utils"
 )
 
+// Numeric semantics of binary operators, both operands have the same type unless noted:
+//
+//	`+ - * / %`  integers  exact result fitted by the overflow policy, `/` truncates, `%` takes
+//	                       the sign of the dividend, dividing by zero is an error
+//	             floats    IEEE 754, `%` is the remainder of a truncated division, as math.Mod
+//	`**`         integers  any integer base, unsigned exponent, fitted by the overflow policy
+//	             floats    float base, integer exponent or a float of the same type
+//	`& | ^`      integers  bitwise on the two's complement representation
+//	`<< >>`      integers  any integer left, unsigned count of any width, the result has the type
+//	                       of the left operand, `>>` is arithmetic for signed integers, shifting
+//	                       by the width or more gives 0, or -1 for negative values shifted right
+//	`== !=`      numbers, strings and bools
+//	`< <= > >=`  numbers
+//
+// Untyped literal shift counts and exponents are u32. Any other combination is an error naming both
+// operand types, `cannot apply << to i32 and i32`. Unary operators are in unary_expr.go.
 type BinaryExpr struct {
 	source utils.String
 	Op     lexer.TokenType
@@ -13,31 +33,43 @@ type BinaryExpr struct {
 	Right  *Expr
 }
 
+func NewBinaryExpr(op lexer.TokenType, left, right Expr) BinaryExpr {
+	return BinaryExpr{
+		source: utils.Encompass(left.Source(), right.Source()),
+		Op:     op,
+		Left:   &left,
+		Right:  &right,
+	}
+}
+
 func (b BinaryExpr) Source() utils.String {
 	return b.source
 }
 
-func (b BinaryExpr) Eval(env *env.Env) (env.Value, error) {
-	left, err := (*b.Left).Eval(env)
-	if err != nil {
-		return nil, err
-	}
-	right, err := (*b.Right).Eval(env)
+func (b BinaryExpr) Eval(e *env.Env) (env.Value, error) {
+	return b.evalAs(e, nil)
+}
+
+// evalAs evaluates the expression where a value of type t is expected, see evalOperands
+func (b BinaryExpr) evalAs(e *env.Env, t env.Type) (env.Value, error) {
+	left, right, err := b.evalOperands(e, t)
 	if err != nil {
 		return nil, err
 	}
 
 	switch b.Op {
 	case lexer.PLUS:
-		return AddValues(left, right, b.Source())
+		return AddValues(left, right, e.Overflow(), b.Source())
 	case lexer.MINUS:
-		return SubtractValues(left, right, b.Source())
+		return SubtractValues(left, right, e.Overflow(), b.Source())
 	case lexer.MULTIPLY:
-		return MultiplyValues(left, right, b.Source())
+		return MultiplyValues(left, right, e.Overflow(), b.Source())
 	case lexer.DIVIDE:
-		return DivideValues(left, right, b.Source())
+		return DivideValues(left, right, e.Overflow(), b.Source())
 	case lexer.MODULO:
-		return ModuloValues(left, right, b.Source())
+		return ModuloValues(left, right, e.Overflow(), b.Source())
+	case lexer.POWER:
+		return PowerValues(left, right, e.Overflow(), b.Source())
 	case lexer.EQUAL:
 		return EqualsValues(left, right, b.Source())
 	case lexer.NOT_EQUAL:
@@ -64,9 +96,88 @@ func (b BinaryExpr) Eval(env *env.Env) (env.Value, error) {
 		return BitwiseLeftShiftValues(left, right, b.Source())
 	case lexer.BITWISE_RIGHT_SHIFT:
 		return BitwiseRightShiftValues(left, right, b.Source())
+	case lexer.RANGE:
+		return RangeValues(left, right, false, b.Source())
+	case lexer.RANGE_INCLUSIVE:
+		return RangeValues(left, right, true, b.Source())
+	case lexer.IN:
+		return InValues(left, right, b.Source())
 	default:
-		return nil, utils.Error{Source: b.Source(), Message: "unsupported binary operator"}
+		return nil, operandError(b.Op, left, right, b.Source())
+	}
+}
+
+// evalOperands evaluates both sides, an untyped number literal takes the type of the other operand,
+// see TypedFromRight. When the result of op has the type of its operands, they are expected to have
+// type t, so that `x : u8 = 2 * 100` multiplies two u8.
+func (b BinaryExpr) evalOperands(e *env.Env, t env.Type) (env.Value, env.Value, error) {
+	if !PreservesType(b.Op) || t == nil || t.BaseType() > env.F64 {
+		t = nil
+	}
+	if !independentOperands(b.Op) && TypedFromRight(*b.Left, *b.Right) {
+		right, err := evalOperand(e, *b.Right, t)
+		if err != nil {
+			return nil, nil, err
+		}
+		left, err := evalOperand(e, *b.Left, OperandType(b.Op, right))
+		return left, right, err
+	}
+	left, err := evalOperand(e, *b.Left, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := evalOperand(e, *b.Right, OperandType(b.Op, left))
+	return left, right, err
+}
+
+// evalOperand evaluates one side of a binary expression, which must have a value
+func evalOperand(e *env.Env, expr Expr, t env.Type) (env.Value, error) {
+	value, err := evalAs(e, expr, t)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, utils.Error{Source: expr.Source(), Message: "expression has no value"}
+	}
+	return value, nil
+}
+
+// PreservesType reports whether the result of op has the type of its left operand
+func PreservesType(op lexer.TokenType) bool {
+	switch op {
+	case lexer.PLUS, lexer.MINUS, lexer.MULTIPLY, lexer.DIVIDE, lexer.MODULO, lexer.POWER,
+		lexer.BITWISE_AND, lexer.BITWISE_OR, lexer.BITWISE_XOR, lexer.BITWISE_LEFT_SHIFT, lexer.BITWISE_RIGHT_SHIFT:
+		return true
+	}
+	return false
+}
+
+// independentOperands reports whether the right operand of op does not share the type of the left one,
+// as for shift counts and exponents
+func independentOperands(op lexer.TokenType) bool {
+	return op == lexer.BITWISE_LEFT_SHIFT || op == lexer.BITWISE_RIGHT_SHIFT || op == lexer.POWER
+}
+
+// OperandType is the type an untyped literal takes when other is the value on the other side of op:
+// u32 for shift counts and integer exponents, the element type for `3 in xs` and range steps,
+// the type of other otherwise
+func OperandType(op lexer.TokenType, other env.Value) env.Type {
+	if other != nil && independentOperands(op) && other.Type().BaseType().IsInteger() {
+		return env.U32
+	}
+	switch o := other.(type) {
+	case nil:
+		return nil
+	case *env.RangeValue:
+		if op == lexer.IN || op == lexer.RANGE {
+			return o.Elem
+		}
+	case *env.ListValue:
+		if op == lexer.IN {
+			return o.Elem
+		}
 	}
+	return env.TypeOf(other)
 }
 
 func addBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string](left, right T, source utils.String) (env.BaseValue[T], error) {
@@ -85,31 +196,112 @@ func divideBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 |
 	return env.NewBaseValue(left/right, source), nil
 }
 
-func moduloBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64](left, right T, source utils.String) (env.BaseValue[T], error) {
-	return env.NewBaseValue(left%right, source), nil
-}
-
-func equalsBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string](left, right T, source utils.String) (env.BaseValue[bool], error) {
+// integerArithmetic computes `left op right` for two integers of the same type exactly, left is nil
+// for a negation. The result is then fitted in that type following the overflow policy.
+func integerArithmetic(op lexer.TokenType, left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	t := right.Type().BaseType()
+	l := new(big.Int)
+	if left != nil {
+		l, _ = env.BigIntValue(left)
+	}
+	r, _ := env.BigIntValue(right)
+	result := new(big.Int)
+	switch op {
+	case lexer.PLUS:
+		result.Add(l, r)
+	case lexer.MINUS:
+		result.Sub(l, r)
+	case lexer.MULTIPLY:
+		result.Mul(l, r)
+	case lexer.DIVIDE, lexer.MODULO:
+		if r.Sign() == 0 {
+			if op == lexer.DIVIDE {
+				return nil, utils.Error{Source: source, Message: "division by zero"}
+			}
+			return nil, utils.Error{Source: source, Message: "modulo by zero"}
+		}
+		// both truncate towards zero, the remainder has the sign of the dividend
+		if op == lexer.DIVIDE {
+			result.Quo(l, r)
+		} else {
+			result.Rem(l, r)
+		}
+	}
+	return fitArithmetic(op, result, t, overflow, left, right, source)
+}
+
+// fitArithmetic builds the value of type t holding the exact result of `left op right`
+func fitArithmetic(op lexer.TokenType, result *big.Int, t env.BaseType, overflow env.OverflowPolicy, left, right env.Value, source utils.String) (env.Value, error) {
+	value, err := env.FitInteger(result, t, overflow.Mode(), source)
+	if err != nil {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s overflows %s, the result %s does not fit", operationName(op, left, right), t.Name(), result)}
+	}
+	return value, nil
+}
+
+// operationName describes an arithmetic operation for error messages, `200 + 100`
+func operationName(op lexer.TokenType, left, right env.Value) string {
+	if left == nil {
+		return OperatorSymbols[op] + "(" + right.String() + ")"
+	}
+	return left.String() + " " + OperatorSymbols[op] + " " + right.String()
+}
+
+// OperatorSymbols spells operators the way they are written in source, for error messages
+var OperatorSymbols = map[lexer.TokenType]string{
+	lexer.PLUS:                  "+",
+	lexer.MINUS:                 "-",
+	lexer.MULTIPLY:              "*",
+	lexer.DIVIDE:                "/",
+	lexer.MODULO:                "%",
+	lexer.POWER:                 "**",
+	lexer.EQUAL:                 "==",
+	lexer.NOT_EQUAL:             "!=",
+	lexer.GREATER_THAN:          ">",
+	lexer.GREATER_THAN_OR_EQUAL: ">=",
+	lexer.LESS_THAN:             "<",
+	lexer.LESS_THAN_OR_EQUAL:    "<=",
+	lexer.AND:                   "and",
+	lexer.OR:                    "or",
+	lexer.NOT:                   "not",
+	lexer.BITWISE_AND:           "&",
+	lexer.BITWISE_OR:            "|",
+	lexer.BITWISE_XOR:           "^",
+	lexer.BITWISE_NOT:           "~",
+	lexer.BITWISE_LEFT_SHIFT:    "<<",
+	lexer.BITWISE_RIGHT_SHIFT:   ">>",
+	lexer.RANGE:                 "..",
+	lexer.RANGE_INCLUSIVE:       "..=",
+	lexer.IN:                    "in",
+}
+
+// operandError reports a binary operator applied to operands it does not support, see the
+// numeric semantics at the top of this file
+func operandError(op lexer.TokenType, left, right env.Value, source utils.String) error {
+	return utils.Error{Source: source, Message: fmt.Sprintf("cannot apply %s to %s and %s", OperatorSymbols[op], typeNameOf(left), typeNameOf(right))}
+}
+
+func equalsBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | env.CodePoint](left, right T, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left == right, source), nil
 }
 
-func notEqualsBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string](left, right T, source utils.String) (env.BaseValue[bool], error) {
+func notEqualsBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | env.CodePoint](left, right T, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left != right, source), nil
 }
 
-func greaterThanBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64](left, right T, source utils.String) (env.BaseValue[bool], error) {
+func greaterThanBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | env.CodePoint](left, right T, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left > right, source), nil
 }
 
-func greaterThanOrEqualBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64](left, right T, source utils.String) (env.BaseValue[bool], error) {
+func greaterThanOrEqualBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | env.CodePoint](left, right T, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left >= right, source), nil
 }
 
-func lessThanBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64](left, right T, source utils.String) (env.BaseValue[bool], error) {
+func lessThanBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | env.CodePoint](left, right T, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left < right, source), nil
 }
 
-func lessThanOrEqualBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64](left, right T, source utils.String) (env.BaseValue[bool], error) {
+func lessThanOrEqualBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | env.CodePoint](left, right T, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left <= right, source), nil
 }
 
@@ -125,14 +317,6 @@ func bitwiseOrBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint3
 	return env.NewBaseValue(left|right, source), nil
 }
 
-func bitwiseLeftShiftBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64](left, right T, source utils.String) (env.BaseValue[T], error) {
-	return env.NewBaseValue(left<<right, source), nil
-}
-
-func bitwiseRightShiftBaseValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64](left, right T, source utils.String) (env.BaseValue[T], error) {
-	return env.NewBaseValue(left>>right, source), nil
-}
-
 func andBaseValues(left, right bool, source utils.String) (env.BaseValue[bool], error) {
 	return env.NewBaseValue(left && right, source), nil
 }
@@ -142,36 +326,18 @@ func orBaseValues(left, right bool, source utils.String) (env.BaseValue[bool], e
 }
 
 // Helper function to add two values
-func AddValues(left, right env.Value, source utils.String) (env.Value, error) {
+func AddValues(left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.PLUS, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in addition"}
+		return nil, operandError(lexer.PLUS, left, right, source)
 	}
 
+	if left.Type().BaseType().IsInteger() {
+		return integerArithmetic(lexer.PLUS, left, right, overflow, source)
+	}
 	switch left.Type().BaseType() {
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.F32:
 		underlyingValues := env.GetBaseTypeValues[float32](left, right)
 		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
@@ -183,40 +349,22 @@ func AddValues(left, right env.Value, source utils.String) (env.Value, error) {
 		return addBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
 
-	return nil, utils.Error{Source: source, Message: "unsupported types for addition"}
+	return nil, operandError(lexer.PLUS, left, right, source)
 }
 
 // Helper function to multiply two values
-func MultiplyValues(left, right env.Value, source utils.String) (env.Value, error) {
+func MultiplyValues(left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.MULTIPLY, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in multiplication"}
+		return nil, operandError(lexer.MULTIPLY, left, right, source)
 	}
 
+	if left.Type().BaseType().IsInteger() {
+		return integerArithmetic(lexer.MULTIPLY, left, right, overflow, source)
+	}
 	switch left.Type().BaseType() {
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.F32:
 		underlyingValues := env.GetBaseTypeValues[float32](left, right)
 		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
@@ -224,39 +372,21 @@ func MultiplyValues(left, right env.Value, source utils.String) (env.Value, erro
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return multiplyBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for multiplication"}
+	return nil, operandError(lexer.MULTIPLY, left, right, source)
 }
 
-func SubtractValues(left, right env.Value, source utils.String) (env.Value, error) {
+func SubtractValues(left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.MINUS, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in subtraction"}
+		return nil, operandError(lexer.MINUS, left, right, source)
 	}
 
+	if left.Type().BaseType().IsInteger() {
+		return integerArithmetic(lexer.MINUS, left, right, overflow, source)
+	}
 	switch left.Type().BaseType() {
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.F32:
 		underlyingValues := env.GetBaseTypeValues[float32](left, right)
 		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
@@ -264,39 +394,21 @@ func SubtractValues(left, right env.Value, source utils.String) (env.Value, erro
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return subtractBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for subtraction"}
+	return nil, operandError(lexer.MINUS, left, right, source)
 }
 
-func DivideValues(left, right env.Value, source utils.String) (env.Value, error) {
+func DivideValues(left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.DIVIDE, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in division"}
+		return nil, operandError(lexer.DIVIDE, left, right, source)
 	}
 
+	if left.Type().BaseType().IsInteger() {
+		return integerArithmetic(lexer.DIVIDE, left, right, overflow, source)
+	}
 	switch left.Type().BaseType() {
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.F32:
 		underlyingValues := env.GetBaseTypeValues[float32](left, right)
 		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
@@ -304,46 +416,41 @@ func DivideValues(left, right env.Value, source utils.String) (env.Value, error)
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return divideBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for division"}
+	return nil, operandError(lexer.DIVIDE, left, right, source)
 }
 
-func ModuloValues(left, right env.Value, source utils.String) (env.Value, error) {
+func ModuloValues(left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in modulo"}
+		return nil, operandError(lexer.MODULO, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return moduloBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.F32:
+		underlyingValues := env.GetBaseTypeValues[float32](left, right)
+		return env.NewF32Value(float32(math.Mod(float64(underlyingValues[0]), float64(underlyingValues[1]))), source), nil
+	case env.F64:
+		underlyingValues := env.GetBaseTypeValues[float64](left, right)
+		return env.NewF64Value(math.Mod(underlyingValues[0], underlyingValues[1]), source), nil
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for modulo"}
+	if left.Type().BaseType().IsInteger() {
+		return integerArithmetic(lexer.MODULO, left, right, overflow, source)
+	}
+	return nil, operandError(lexer.MODULO, left, right, source)
 }
 
 func EqualsValues(left, right env.Value, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.EQUAL, left, right, source); ok {
+		return result, err
+	}
+	if !left.IsBase() || !right.IsBase() {
+		equal, err := structuralEquals(left, right, source)
+		if err != nil {
+			return nil, err
+		}
+		return env.NewBoolValue(equal, source), nil
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in equality"}
+		return nil, operandError(lexer.EQUAL, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -377,16 +484,76 @@ func EqualsValues(left, right env.Value, source utils.String) (env.Value, error)
 	case env.F64:
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return equalsBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Char:
+		underlyingValues := env.GetBaseTypeValues[env.CodePoint](left, right)
+		return equalsBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.Str:
 		underlyingValues := env.GetBaseTypeValues[string](left, right)
 		return equalsBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Bool:
+		underlyingValues := env.GetBaseTypeValues[bool](left, right)
+		return equalsBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for equality"}
+	return nil, operandError(lexer.EQUAL, left, right, source)
+}
+
+// structuralEquals compares compound values element by element
+func structuralEquals(left, right env.Value, source utils.String) (bool, error) {
+	// an optional is only equal to none when it holds none
+	if env.IsNone(left) || env.IsNone(right) {
+		return env.IsNone(left) && env.IsNone(right), nil
+	}
+	if left == nil || right == nil || !env.SameType(left.Type(), right.Type()) {
+		return false, operandError(lexer.EQUAL, left, right, source)
+	}
+	var leftItems, rightItems []env.Value
+	switch l := left.(type) {
+	case *env.TupleValue:
+		leftItems, rightItems = l.Items, right.(*env.TupleValue).Items
+	case *env.ListValue:
+		leftItems, rightItems = l.Items, right.(*env.ListValue).Items
+	case *env.StructValue:
+		leftItems, rightItems = l.Fields, right.(*env.StructValue).Fields
+	case *env.EnumValue:
+		r := right.(*env.EnumValue)
+		if l.Variant != r.Variant {
+			return false, nil
+		}
+		leftItems, rightItems = l.Payload, r.Payload
+	case *env.RangeValue:
+		r := right.(*env.RangeValue)
+		return l.Start == r.Start && l.End == r.End && l.Step == r.Step && l.Inclusive == r.Inclusive, nil
+	default:
+		return false, operandError(lexer.EQUAL, left, right, source)
+	}
+	if len(leftItems) != len(rightItems) {
+		return false, nil
+	}
+	for i := range leftItems {
+		equal, err := EqualsValues(leftItems[i], rightItems[i], source)
+		if err != nil {
+			return false, err
+		}
+		if !equal.(env.BaseValue[bool]).GetValue() {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 func NotEqualsValues(left, right env.Value, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.NOT_EQUAL, left, right, source); ok {
+		return result, err
+	}
+	if !left.IsBase() || !right.IsBase() {
+		equal, err := structuralEquals(left, right, source)
+		if err != nil {
+			return nil, err
+		}
+		return env.NewBoolValue(!equal, source), nil
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in inequality"}
+		return nil, operandError(lexer.NOT_EQUAL, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -420,16 +587,25 @@ func NotEqualsValues(left, right env.Value, source utils.String) (env.Value, err
 	case env.F64:
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return notEqualsBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Char:
+		underlyingValues := env.GetBaseTypeValues[env.CodePoint](left, right)
+		return notEqualsBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.Str:
 		underlyingValues := env.GetBaseTypeValues[string](left, right)
 		return notEqualsBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Bool:
+		underlyingValues := env.GetBaseTypeValues[bool](left, right)
+		return notEqualsBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for inequality"}
+	return nil, operandError(lexer.NOT_EQUAL, left, right, source)
 }
 
 func GreaterThanValues(left, right env.Value, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.GREATER_THAN, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in greater than"}
+		return nil, operandError(lexer.GREATER_THAN, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -463,13 +639,19 @@ func GreaterThanValues(left, right env.Value, source utils.String) (env.Value, e
 	case env.F64:
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return greaterThanBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Char:
+		underlyingValues := env.GetBaseTypeValues[env.CodePoint](left, right)
+		return greaterThanBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for greater than"}
+	return nil, operandError(lexer.GREATER_THAN, left, right, source)
 }
 
 func GreaterThanOrEqualValues(left, right env.Value, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.GREATER_THAN_OR_EQUAL, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in greater than or equal"}
+		return nil, operandError(lexer.GREATER_THAN_OR_EQUAL, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -503,14 +685,20 @@ func GreaterThanOrEqualValues(left, right env.Value, source utils.String) (env.V
 	case env.F64:
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return greaterThanOrEqualBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Char:
+		underlyingValues := env.GetBaseTypeValues[env.CodePoint](left, right)
+		return greaterThanOrEqualBaseValues(underlyingValues[0], underlyingValues[1], source)
 	default:
-		return nil, utils.Error{Source: source, Message: "unsupported types for greater than or equal"}
+		return nil, operandError(lexer.GREATER_THAN_OR_EQUAL, left, right, source)
 	}
 }
 
 func LessThanValues(left, right env.Value, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.LESS_THAN, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in less than"}
+		return nil, operandError(lexer.LESS_THAN, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -544,13 +732,19 @@ func LessThanValues(left, right env.Value, source utils.String) (env.Value, erro
 	case env.F64:
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return lessThanBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Char:
+		underlyingValues := env.GetBaseTypeValues[env.CodePoint](left, right)
+		return lessThanBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for less than"}
+	return nil, operandError(lexer.LESS_THAN, left, right, source)
 }
 
 func LessThanOrEqualValues(left, right env.Value, source utils.String) (env.Value, error) {
+	if result, ok, err := overloadedOperator(lexer.LESS_THAN_OR_EQUAL, left, right, source); ok {
+		return result, err
+	}
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in less than or equal"}
+		return nil, operandError(lexer.LESS_THAN_OR_EQUAL, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -584,13 +778,16 @@ func LessThanOrEqualValues(left, right env.Value, source utils.String) (env.Valu
 	case env.F64:
 		underlyingValues := env.GetBaseTypeValues[float64](left, right)
 		return lessThanOrEqualBaseValues(underlyingValues[0], underlyingValues[1], source)
+	case env.Char:
+		underlyingValues := env.GetBaseTypeValues[env.CodePoint](left, right)
+		return lessThanOrEqualBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for less than or equal"}
+	return nil, operandError(lexer.LESS_THAN_OR_EQUAL, left, right, source)
 }
 
 func AndValues(left, right env.Value, source utils.String) (env.Value, error) {
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in greater than or equal"}
+		return nil, operandError(lexer.AND, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -598,13 +795,13 @@ func AndValues(left, right env.Value, source utils.String) (env.Value, error) {
 		underlyingValues := env.GetBaseTypeValues[bool](left, right)
 		return andBaseValues(underlyingValues[0], underlyingValues[1], source)
 	default:
-		return nil, utils.Error{Source: source, Message: "unsupported types for greater than or equal"}
+		return nil, operandError(lexer.AND, left, right, source)
 	}
 }
 
 func OrValues(left, right env.Value, source utils.String) (env.Value, error) {
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in greater than or equal"}
+		return nil, operandError(lexer.OR, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -612,47 +809,47 @@ func OrValues(left, right env.Value, source utils.String) (env.Value, error) {
 		underlyingValues := env.GetBaseTypeValues[bool](left, right)
 		return orBaseValues(underlyingValues[0], underlyingValues[1], source)
 	default:
-		return nil, utils.Error{Source: source, Message: "unsupported types for greater than or equal"}
+		return nil, operandError(lexer.OR, left, right, source)
 	}
 }
 
 func BitwiseXorValues(left, right env.Value, source utils.String) (env.Value, error) {
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in division"}
+		return nil, operandError(lexer.BITWISE_XOR, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
 	case env.I8:
 		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.I16:
 		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.I32:
 		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.I64:
 		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.U8:
 		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.U16:
 		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.U32:
 		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	case env.U64:
 		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
+		return bitwiseXorBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for division"}
+	return nil, operandError(lexer.BITWISE_XOR, left, right, source)
 }
 
 func BitwiseAndValues(left, right env.Value, source utils.String) (env.Value, error) {
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in division"}
+		return nil, operandError(lexer.BITWISE_AND, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -681,12 +878,12 @@ func BitwiseAndValues(left, right env.Value, source utils.String) (env.Value, er
 		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
 		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for division"}
+	return nil, operandError(lexer.BITWISE_AND, left, right, source)
 }
 
 func BitwiseOrValues(left, right env.Value, source utils.String) (env.Value, error) {
 	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in division"}
+		return nil, operandError(lexer.BITWISE_OR, left, right, source)
 	}
 
 	switch left.Type().BaseType() {
@@ -715,73 +912,112 @@ func BitwiseOrValues(left, right env.Value, source utils.String) (env.Value, err
 		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
 		return bitwiseOrBaseValues(underlyingValues[0], underlyingValues[1], source)
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for division"}
+	return nil, operandError(lexer.BITWISE_OR, left, right, source)
 }
 
+// BitwiseLeftShiftValues shifts an integer of any type by an unsigned count of any type,
+// bits shifted past the width of the type are lost
 func BitwiseLeftShiftValues(left, right env.Value, source utils.String) (env.Value, error) {
-	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in division"}
-	}
-
-	switch left.Type().BaseType() {
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return bitwiseLeftShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for division"}
+	return shiftValues(lexer.BITWISE_LEFT_SHIFT, left, right, source)
 }
 
+// BitwiseRightShiftValues shifts an integer of any type by an unsigned count of any type,
+// signed integers keep their sign
 func BitwiseRightShiftValues(left, right env.Value, source utils.String) (env.Value, error) {
-	if (!left.IsBase() || !right.IsBase()) || (left.Type().BaseType() != right.Type().BaseType()) {
-		return nil, utils.Error{Source: source, Message: "type mismatch in division"}
-	}
-
-	switch left.Type().BaseType() {
-	case env.I8:
-		underlyingValues := env.GetBaseTypeValues[int8](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I16:
-		underlyingValues := env.GetBaseTypeValues[int16](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I32:
-		underlyingValues := env.GetBaseTypeValues[int32](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.I64:
-		underlyingValues := env.GetBaseTypeValues[int64](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U8:
-		underlyingValues := env.GetBaseTypeValues[uint8](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U16:
-		underlyingValues := env.GetBaseTypeValues[uint16](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U32:
-		underlyingValues := env.GetBaseTypeValues[uint32](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	case env.U64:
-		underlyingValues := env.GetBaseTypeValues[uint64](left, right)
-		return bitwiseRightShiftBaseValues(underlyingValues[0], underlyingValues[1], source)
-	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for division"}
+	return shiftValues(lexer.BITWISE_RIGHT_SHIFT, left, right, source)
+}
+
+func shiftValues(op lexer.TokenType, left, right env.Value, source utils.String) (env.Value, error) {
+	if left == nil || right == nil || !left.Type().BaseType().IsInteger() || !isUnsigned(right) {
+		return nil, operandError(op, left, right, source)
+	}
+	count, _ := env.BigIntValue(right)
+	if !count.IsUint64() {
+		return nil, operandError(op, left, right, source)
+	}
+	shiftLeft := op == lexer.BITWISE_LEFT_SHIFT
+	switch value := left.(type) {
+	case env.BaseValue[int8]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[int16]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[int32]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[int64]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[uint8]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[uint16]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[uint32]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	case env.BaseValue[uint64]:
+		return shiftBaseValue(value.GetValue(), count.Uint64(), shiftLeft, source), nil
+	}
+	return nil, operandError(op, left, right, source)
+}
+
+func isUnsigned(v env.Value) bool {
+	t := v.Type().BaseType()
+	return t >= env.U8 && t <= env.U64
+}
+
+// PowerValues raises an integer to an unsigned power of any type following the overflow policy,
+// or a float to a power of the same float type or of any integer type
+func PowerValues(left, right env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
+	if left == nil || right == nil || !left.IsBase() || !right.IsBase() {
+		return nil, operandError(lexer.POWER, left, right, source)
+	}
+	t := left.Type().BaseType()
+	switch {
+	case t.IsInteger() && isUnsigned(right):
+		base, _ := env.BigIntValue(left)
+		exponent, _ := env.BigIntValue(right)
+		return integerPower(base, exponent, t, overflow, left, right, source)
+	case t.IsFloat() && (right.Type().BaseType() == t || right.Type().BaseType().IsInteger()):
+		base, _ := env.FloatValue(left)
+		exponent, ok := env.FloatValue(right)
+		if !ok {
+			i, _ := env.BigIntValue(right)
+			exponent, _ = new(big.Float).SetInt(i).Float64()
+		}
+		if t == env.F32 {
+			return env.NewF32Value(float32(math.Pow(base, exponent)), source), nil
+		}
+		return env.NewF64Value(math.Pow(base, exponent), source), nil
+	}
+	return nil, operandError(lexer.POWER, left, right, source)
+}
+
+// integerPower computes base ** exponent for integers of type t. Results are only computed
+// exactly while they are small enough to matter, larger ones overflow every integer type.
+func integerPower(base, exponent *big.Int, t env.BaseType, overflow env.OverflowPolicy, left, right env.Value, source utils.String) (env.Value, error) {
+	if base.CmpAbs(big.NewInt(1)) <= 0 || exponent.Cmp(big.NewInt(128)) <= 0 {
+		return fitArithmetic(lexer.POWER, new(big.Int).Exp(base, exponent, nil), t, overflow, left, right, source)
+	}
+	if overflow == env.Wrap {
+		// the low bits of the result only depend on the low bits of the factors
+		modulus := new(big.Int).Lsh(big.NewInt(1), 64)
+		result := new(big.Int).Exp(new(big.Int).Abs(base), exponent, modulus)
+		if base.Sign() < 0 && exponent.Bit(0) == 1 {
+			result.Neg(result)
+		}
+		return env.FitInteger(result, t, env.Wrapping, source)
+	}
+	if overflow == env.Trap {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s overflows %s", operationName(lexer.POWER, left, right), t.Name())}
+	}
+	// far out of range, only its sign matters
+	huge := new(big.Int).Lsh(big.NewInt(1), 128)
+	if base.Sign() < 0 && exponent.Bit(0) == 1 {
+		huge.Neg(huge)
+	}
+	return env.FitInteger(huge, t, env.Saturating, source)
+}
+
+func shiftBaseValue[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64](value T, count uint64, shiftLeft bool, source utils.String) env.Value {
+	if shiftLeft {
+		return env.NewBaseValue(value<<count, source)
+	}
+	return env.NewBaseValue(value>>count, source)
 }
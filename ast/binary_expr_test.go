@@ -0,0 +1,49 @@
+package ast_test
+
+import (
+	"testing"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/parser"
+	"com.loop.anonx3247/utils"
+)
+
+// evalProgram evaluates source without checking it first, as the runtime must stand on its own
+func evalProgram(t *testing.T, source string) (env.Value, error) {
+	t.Helper()
+	p, err := parser.NewParser(source)
+	if err != nil {
+		t.Fatalf("parsing %q failed: %v", source, err)
+	}
+	program, err := p.Parse()
+	if err != nil {
+		t.Fatalf("parsing %q failed: %v", source, err)
+	}
+	return program.EvalIn(env.NewEnv())
+}
+
+func TestBinaryOperandWithoutValue(t *testing.T) {
+	tests := []struct {
+		source string
+		at     string // the operand the error points at
+	}{
+		{"fn f() {}\nf() + 1", "f()"},
+		{"fn f() {}\n1 + f()", "f()"},
+		{"fn f() {}\nf() == 1", "f()"},
+		{"fn f() {}\nf() in [1]", "f()"},
+		{"fn f() {}\nf() and true", "f()"},
+		{"fn f() {}\n2 ** f()", "f()"},
+		{"print(1) + 1", "print(1)"},
+	}
+	for _, test := range tests {
+		_, err := evalProgram(t, test.source)
+		e, ok := err.(utils.Error)
+		if !ok {
+			t.Errorf("evaluating %q gives %v, want an error", test.source, err)
+			continue
+		}
+		if e.Message != "expression has no value" || e.Source.String() != test.at {
+			t.Errorf("evaluating %q reports %q at %q, want %q at %q", test.source, e.Message, e.Source.String(), "expression has no value", test.at)
+		}
+	}
+}
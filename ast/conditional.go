@@ -13,21 +13,25 @@ type ConditionalExpr struct {
 }
 
 func (c ConditionalExpr) Eval(env *envv.Env) (envv.Value, error) {
+	return c.evalAs(env, nil)
+}
+
+// evalAs evaluates the branch that is taken where a value of type t is expected
+func (c ConditionalExpr) evalAs(env *envv.Env, t envv.Type) (envv.Value, error) {
 	condition, err := c.Condition.Eval(env)
 	if err != nil {
 		return nil, err
 	}
 
-	if !condition.IsBase() {
-		return nil, utils.Error{Source: condition.Source(), Message: "condition is not a boolean"}
+	conditionValue, ok := condition.(envv.BaseValue[bool])
+	if !ok {
+		return nil, utils.Error{Source: c.Condition.Source(), Message: "condition is not a boolean"}
 	}
 
-	conditionValue := condition.(envv.BaseValue[bool])
-
 	if conditionValue.GetValue() {
-		return c.Content.Eval(env)
+		return c.Content.evalInAs(env.NewChild(), t)
 	} else if c.Next != nil {
-		return c.Next.Eval(env)
+		return c.Next.evalAs(env, t)
 	}
 	return nil, nil
 }
@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// ConversionExpr converts a value to a base type, `x as u8`, `f64(x)` or `u8.saturating(x)`
+type ConversionExpr struct {
+	source utils.String
+	Value  Expr
+	Type   env.BaseType
+	Mode   env.ConversionMode
+}
+
+func NewConversionExpr(value Expr, t env.BaseType, mode env.ConversionMode, source utils.String) ConversionExpr {
+	return ConversionExpr{source: source, Value: value, Type: t, Mode: mode}
+}
+
+func (c ConversionExpr) Source() utils.String {
+	return c.source
+}
+
+func (c ConversionExpr) Eval(e *env.Env) (env.Value, error) {
+	value, err := c.Value.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, utils.Error{Source: c.Value.Source(), Message: "expression has no value"}
+	}
+	return env.Convert(value, c.Type, c.Mode, c.source)
+}
@@ -0,0 +1,79 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// EnumDecl declares an enum (algebraic data type)
+type EnumDecl struct {
+	source   utils.String
+	Name     string
+	Variants []env.Variant
+}
+
+func NewEnumDecl(name string, variants []env.Variant, source utils.String) EnumDecl {
+	return EnumDecl{source: source, Name: name, Variants: variants}
+}
+
+func (d EnumDecl) Source() utils.String {
+	return d.source
+}
+
+func (d EnumDecl) Eval(e *env.Env) (env.Value, error) {
+	enumType := env.NewEnumType(d.Name, d.Variants, d.source)
+	e.Set(d.Name, enumType, true)
+	return enumType, nil
+}
+
+// variantValue reads `Enum.Variant`, unit variants are values while
+// tuple variants are constructor functions
+func variantValue(enumType *env.EnumType, name string, source utils.String) (env.Value, error) {
+	i := enumType.VariantIndex(name)
+	if i < 0 {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s has no variant %s", enumType.Name(), name)}
+	}
+	if enumType.Variants[i].IsUnit() {
+		return env.NewEnumValue(enumType, i, nil, source), nil
+	}
+	return &env.BuiltinFunction{
+		Name:   enumType.Name() + "." + name,
+		Params: enumType.Variants[i].Payload,
+		Call: func(args []env.Value, source utils.String) (env.Value, error) {
+			return constructVariant(enumType, i, args, source)
+		},
+	}, nil
+}
+
+// constructAnonymousVariant builds `Enum(a, b, c)`, the variant is chosen by the types of the arguments
+func constructAnonymousVariant(enumType *env.EnumType, args []Argument, source utils.String) (env.Value, error) {
+	if err := expectPositional(args); err != nil {
+		return nil, err
+	}
+	values := make([]env.Value, len(args))
+	types := make([]env.Type, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+		types[i] = env.TypeOf(arg.Value)
+	}
+	i := enumType.AnonymousVariant(types)
+	if i < 0 {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s has no variant taking %s", enumType.Name(), env.TupleType{Elems: types}.Name())}
+	}
+	return constructVariant(enumType, i, values, source)
+}
+
+func constructVariant(enumType *env.EnumType, variant int, args []env.Value, source utils.String) (env.Value, error) {
+	payload := enumType.Variants[variant].Payload
+	if len(args) != len(payload) {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("expected %d arguments, got %d", len(payload), len(args))}
+	}
+	for i, arg := range args {
+		if !env.SameType(arg.Type(), payload[i]) {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("expected %s for payload %d, got %s", payload[i].Name(), i, arg.Type().Name())}
+		}
+	}
+	return env.NewEnumValue(enumType, variant, args, source), nil
+}
@@ -0,0 +1,61 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// FieldExpr reads `target.field`, tuple elements are accessed by position (`t.0`)
+type FieldExpr struct {
+	source utils.String
+	Target Expr
+	Field  string
+}
+
+func NewFieldExpr(target Expr, field string, source utils.String) FieldExpr {
+	return FieldExpr{source: source, Target: target, Field: field}
+}
+
+func (f FieldExpr) Source() utils.String {
+	return f.source
+}
+
+func (f FieldExpr) Eval(e *env.Env) (env.Value, error) {
+	target, err := f.Target.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	return FieldValue(target, f.Field, f.source)
+}
+
+// FieldValue reads the field of target
+func FieldValue(target env.Value, field string, source utils.String) (env.Value, error) {
+	switch t := target.(type) {
+	case *env.TupleValue:
+		i, err := strconv.Atoi(field)
+		if err != nil || i < 0 || i >= len(t.Items) {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("tuple %s has no field %s", t.Type().Name(), field)}
+		}
+		return t.Items[i], nil
+	case *env.StructValue:
+		if value, ok := t.Get(field); ok {
+			return value, nil
+		}
+	case *env.EnumType:
+		return variantValue(t, field, source)
+	case *env.ModuleValue:
+		return t.Member(field, source)
+	case env.TypeArgValue:
+		// associated constants, `T.zero`
+		for _, trait := range t.Traits {
+			if trait.HasConstant(field) {
+				return trait.Constant(t.Arg, field, source)
+			}
+		}
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s has no constant %s", t.Param.Name, field)}
+	}
+	return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s has no field %s", typeNameOf(target), field)}
+}
@@ -0,0 +1,552 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+type FunctionDecl struct {
+	source     utils.String
+	Name       string
+	TypeParams []env.TypeParam // `<T implements Addition>`, nil unless the function is generic
+	Params     []env.Param
+	Return     env.Type // nil when the return type is inferred
+	Body       Expr
+}
+
+func NewFunctionDecl(name string, typeParams []env.TypeParam, params []env.Param, ret env.Type, body Expr, source utils.String) FunctionDecl {
+	return FunctionDecl{source: source, Name: name, TypeParams: typeParams, Params: params, Return: ret, Body: body}
+}
+
+func (f FunctionDecl) Source() utils.String {
+	return f.source
+}
+
+func (f FunctionDecl) Eval(e *env.Env) (env.Value, error) {
+	fn := env.NewFunctionValue(f.Name, f.TypeParams, f.Params, f.Return, f.Body, e, f.source)
+	if f.Name != "" {
+		e.Set(f.Name, fn, true)
+	}
+	return fn, nil
+}
+
+type CallExpr struct {
+	source utils.String
+	Callee Expr
+	Args   []CallArg
+}
+
+// CallArg is an argument as written at the call site, Name is empty for positional arguments
+type CallArg struct {
+	source utils.String
+	Name   string
+	Value  Expr
+}
+
+func NewCallArg(name string, value Expr, source utils.String) CallArg {
+	return CallArg{source: source, Name: name, Value: value}
+}
+
+func (a CallArg) Source() utils.String {
+	return a.source
+}
+
+func NewCallExpr(callee Expr, args []CallArg, source utils.String) CallExpr {
+	return CallExpr{source: source, Callee: callee, Args: args}
+}
+
+func (c CallExpr) Source() utils.String {
+	return c.source
+}
+
+func (c CallExpr) Eval(e *env.Env) (env.Value, error) {
+	if field, ok := c.Callee.(FieldExpr); ok {
+		return c.evalMethodCall(e, field)
+	}
+	callee, err := c.Callee.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Args) > 0 {
+		if err := checkMutatedArg(e, callee, c.Args[0].Value); err != nil {
+			return nil, err
+		}
+	}
+	args, err := c.evalArgs(e, ArgumentTypes(callee, c.Args, 0))
+	if err != nil {
+		return nil, err
+	}
+	return CallValue(callee, args, c.source)
+}
+
+// evalMethodCall resolves `target.name(args)`: a field holding a function is called
+// directly, otherwise the call becomes `name(target, args)` with name a method given to target
+// by an impl or a function in scope (uniform function call syntax)
+func (c CallExpr) evalMethodCall(e *env.Env, field FieldExpr) (env.Value, error) {
+	target, err := field.Target.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, utils.Error{Source: field.Target.Source(), Message: "expression has no value"}
+	}
+	callee, method, err := c.methodCallee(e, target, field)
+	if err != nil {
+		return nil, err
+	}
+	offset := 0
+	if method {
+		offset = 1
+		if err := checkMutatedArg(e, callee, field.Target); err != nil {
+			return nil, err
+		}
+	}
+	args, err := c.evalArgs(e, ArgumentTypes(callee, c.Args, offset))
+	if err != nil {
+		return nil, err
+	}
+	if method {
+		receiver := Argument{Value: target, Source: field.Target.Source()}
+		receiver.Root, receiver.Shared = argumentPlace(e, field.Target)
+		args = append([]Argument{receiver}, args...)
+	}
+	return CallValue(callee, args, c.source)
+}
+
+// checkMutatedArg makes sure the first argument of a builtin changing it in place, `push(xs, 1)`,
+// is not reached through an immutable variable, as for `xs[0] = 1`
+func checkMutatedArg(e *env.Env, callee env.Value, arg Expr) error {
+	builtin, ok := callee.(*env.BuiltinFunction)
+	if !ok || !builtin.Mutates {
+		return nil
+	}
+	return checkMutableRoot(e, arg, "call "+builtin.Name+" on")
+}
+
+// methodCallee finds the function called by `target.name(args)`, method is true
+// when target is passed as its first argument
+func (c CallExpr) methodCallee(e *env.Env, target env.Value, field FieldExpr) (callee env.Value, method bool, err error) {
+	switch t := target.(type) {
+	case *env.StructValue:
+		if callee, ok := t.Get(field.Field); ok {
+			return callee, false, nil
+		}
+	case *env.EnumType:
+		callee, err := variantValue(t, field.Field, field.Source())
+		return callee, false, err
+	case *env.ModuleValue:
+		callee, err := t.Member(field.Field, field.Source())
+		return callee, false, err
+	}
+	if callee, ok := e.Method(target.Type(), field.Field); ok {
+		return callee, true, nil
+	}
+	callee, ok := e.Get(field.Field)
+	if !ok {
+		return nil, false, utils.Error{Source: field.Source(), Message: fmt.Sprintf("%s has no field or function named %s", target.Type().Name(), field.Field)}
+	}
+	return callee, true, nil
+}
+
+// ArgumentTypes returns the type expected for each of args when calling callee, nil where unknown.
+// offset is the number of arguments passed before args, the target of a method call.
+func ArgumentTypes(callee env.Value, args []CallArg, offset int) []env.Type {
+	var params []env.Type
+	switch fn := callee.(type) {
+	case *env.FunctionValue:
+		// type parameters are only known once the arguments are evaluated
+		fnType := fn.Type().(env.FunctionType)
+		names := make([]string, len(args))
+		for i, arg := range args {
+			names[i] = arg.Name
+		}
+		return ParamTypes(env.NewTypeBindings(fn.TypeParams).Substitute(fnType).(env.FunctionType), names, offset)
+	case *env.BuiltinFunction:
+		params = fn.Params
+	case *env.StructType:
+		types := make([]env.Type, len(args))
+		for i, arg := range args {
+			if j := fn.FieldIndex(arg.Name); j >= 0 {
+				types[i] = fn.Fields[j].Type
+			}
+		}
+		return types
+	case *env.EnumType:
+		// an untyped literal can only select an anonymous variant when there is one of that length
+		for _, variant := range fn.Variants {
+			if variant.Name == "" && len(variant.Payload) == len(args) {
+				if params != nil {
+					return nil
+				}
+				params = variant.Payload
+			}
+		}
+		offset = 0
+	}
+	if len(params) != len(args)+offset {
+		return nil
+	}
+	return params[offset:]
+}
+
+// evalArgs evaluates the arguments, types holds the type expected for each of them or is nil
+func (c CallExpr) evalArgs(e *env.Env, types []env.Type) ([]Argument, error) {
+	args := make([]Argument, len(c.Args))
+	for i, arg := range c.Args {
+		var t env.Type
+		if types != nil {
+			t = types[i]
+		}
+		value, err := evalAs(e, arg.Value, t)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, utils.Error{Source: arg.Source(), Message: "expression has no value"}
+		}
+		args[i] = Argument{Name: arg.Name, Value: value, Source: arg.Source()}
+		args[i].Root, args[i].Shared = argumentPlace(e, arg.Value)
+	}
+	return args, nil
+}
+
+// Argument is an evaluated call argument along with the expression it came from
+type Argument struct {
+	Name   string
+	Value  env.Value
+	Source utils.String
+	// a mut parameter changes the comps and lists of a Shared argument in place, the argument is a
+	// mutable variable or a part of one. It cannot change those of an argument that is a part of
+	// the immutable variable Root, and works on a copy of the others.
+	Root   string
+	Shared bool
+}
+
+// argumentPlace tells whether an argument is a part of a variable, see Argument
+func argumentPlace(e *env.Env, expr Expr) (root string, shared bool) {
+	id, ok := placeRoot(expr)
+	if !ok {
+		return "", false
+	}
+	v, ok := e.Lookup(id.Name())
+	if !ok || !v.Const {
+		return "", ok
+	}
+	return id.Name(), false
+}
+
+// MaxCallDepth is the number of nested function calls a program may make, past it a call is an error
+// rather than overflowing the stack of the interpreter
+const MaxCallDepth = 10000
+
+// callDepth counts the function calls being evaluated
+var callDepth int
+
+// CallValue calls a function value with already evaluated arguments
+func CallValue(callee env.Value, args []Argument, source utils.String) (env.Value, error) {
+	// `Self(x: 1)` in an impl for a comp constructs it
+	if arg, ok := callee.(env.TypeArgValue); ok {
+		if value, ok := arg.Arg.(env.Value); ok {
+			callee = value
+		}
+	}
+	switch fn := callee.(type) {
+	case *env.StructType:
+		return constructStruct(fn, args, source)
+	case *env.EnumType:
+		return constructAnonymousVariant(fn, args, source)
+	case *env.BuiltinFunction:
+		if err := expectPositional(args); err != nil {
+			return nil, err
+		}
+		values := make([]env.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		return fn.Call(values, source)
+	case *env.FunctionValue:
+		if callDepth >= MaxCallDepth {
+			return nil, utils.Error{Source: source, Message: "recursion too deep"}
+		}
+		callDepth++
+		defer func() { callDepth-- }()
+		given, err := paramArgs(fn.Params, args, source)
+		if err != nil {
+			return nil, err
+		}
+		callEnv := fn.Env.NewChild()
+		bindings, err := bindTypeArgs(fn, given, callEnv, source)
+		if err != nil {
+			return nil, err
+		}
+		for i, param := range fn.Params {
+			paramType := bindings.Substitute(param.Type)
+			arg, err := paramValue(callEnv, param, paramType, given[i], source)
+			if err != nil {
+				return nil, err
+			}
+			if !env.SameType(arg.Value.Type(), paramType) {
+				return nil, utils.Error{Source: arg.Source, Message: fmt.Sprintf("expected %s for parameter %s, got %s", paramType.Name(), param.Name, arg.Value.Type().Name())}
+			}
+			if param.Mutable && !arg.Shared && env.MutableInPlace(arg.Value) {
+				if arg.Root != "" {
+					return nil, utils.Error{Source: arg.Source, Message: fmt.Sprintf("cannot pass immutable variable %s to mut parameter %s, declare it with mut", arg.Root, param.Name)}
+				}
+				arg.Value = env.Copy(arg.Value)
+			}
+			callEnv.Declare(param.Name, arg.Value, paramType, !param.Mutable)
+		}
+		ret := bindings.Substitute(fn.Return)
+		result, err := evalBody(callEnv, fn.Body, ret)
+		switch signal := err.(type) {
+		case returnSignal:
+			result, err = signal.value, nil
+			if value, ok, coerceErr := CoerceLiteral(signal.expr, ret); ok {
+				result, err = value, coerceErr
+			}
+		case breakSignal:
+			// loops do not extend across function calls
+			return nil, utils.Error{Source: signal.source, Message: "break outside of a loop"}
+		case continueSignal:
+			return nil, utils.Error{Source: signal.source, Message: "continue outside of a loop"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ret != nil && (result == nil || !env.SameType(result.Type(), ret)) {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s should return %s, got %s", fn, ret.Name(), typeNameOf(result))}
+		}
+		return result, nil
+	}
+	if callee == nil {
+		return nil, utils.Error{Source: source, Message: "cannot call an expression without value"}
+	}
+	return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot call a value of type %s", callee.Type().Name())}
+}
+
+// bindTypeArgs infers the type arguments of a call of a generic function from the arguments given
+// to its parameters, checks them against their bounds and declares them in the environment of the call
+func bindTypeArgs(fn *env.FunctionValue, given []*Argument, callEnv *env.Env, source utils.String) (env.TypeBindings, error) {
+	bindings := env.NewTypeBindings(fn.TypeParams)
+	sources := map[string]utils.String{}
+	for i, param := range fn.Params {
+		if given[i] == nil {
+			continue
+		}
+		bindings.Infer(param.Type, env.TypeOf(given[i].Value))
+		// bound errors point at the argument a type parameter is inferred from
+		for name, arg := range bindings {
+			if _, ok := sources[name]; !ok && arg != nil {
+				sources[name] = given[i].Source
+			}
+		}
+	}
+	for _, param := range fn.TypeParams {
+		arg := bindings[param.Name]
+		if arg == nil {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot infer %s from the arguments of %s", param.Name, fn)}
+		}
+		traits := make([]*env.TraitType, len(param.Bounds))
+		for i, bound := range param.Bounds {
+			value, _ := fn.Env.Get(bound)
+			trait, ok := value.(*env.TraitType)
+			if !ok {
+				return nil, utils.Error{Source: fn.Source(), Message: "unknown trait " + bound}
+			}
+			if !trait.ImplementedBy(arg) {
+				return nil, utils.Error{Source: sources[param.Name], Message: fmt.Sprintf("%s does not implement %s, required by %s", arg.Name(), bound, param.Name)}
+			}
+			traits[i] = trait
+		}
+		callEnv.Set(param.Name, env.NewTypeArgValue(param, arg, traits, source), true)
+	}
+	return bindings, nil
+}
+
+// resolveTypeArgs replaces the type parameters of the generic call being evaluated in t, as in
+// `s : T = T.zero`
+func resolveTypeArgs(e *env.Env, t env.Type) env.Type {
+	return env.SubstituteNamed(t, func(name string) (env.Type, bool) {
+		value, _ := e.Get(name)
+		arg, ok := value.(env.TypeArgValue)
+		return arg.Arg, ok
+	})
+}
+
+// evalBody evaluates the body of a function returning a value of type ret
+func evalBody(e *env.Env, body env.Body, ret env.Type) (env.Value, error) {
+	if expr, ok := body.(Expr); ok {
+		return evalAs(e, expr, ret)
+	}
+	return body.Eval(e)
+}
+
+func expectPositional(args []Argument) error {
+	for _, arg := range args {
+		if arg.Name != "" {
+			return utils.Error{Source: arg.Source, Message: "named arguments are only supported by comps and functions with named parameters"}
+		}
+	}
+	return nil
+}
+
+// MatchArgs matches the arguments of a call to the parameters of fn, given the name of each
+// argument, empty for positional ones, and its source. Positional arguments fill the positional
+// parameters in order and named ones the parameters of the `{...}` groups. It returns the index of
+// the argument given to each positional parameter then to each named one, -1 when omitted.
+func MatchArgs(fn env.FunctionType, names []string, sources []utils.String, source utils.String) ([]int, error) {
+	matched := make([]int, len(fn.Params)+len(fn.Named))
+	for i := range matched {
+		matched[i] = -1
+	}
+	positional := 0
+	for i, name := range names {
+		if name == "" {
+			if positional < len(fn.Params) {
+				matched[positional] = i
+			}
+			positional++
+			continue
+		}
+		j := namedParamIndex(fn, name)
+		if j < 0 {
+			return nil, utils.Error{Source: sources[i], Message: "no named parameter " + name}
+		}
+		if matched[len(fn.Params)+j] >= 0 {
+			return nil, utils.Error{Source: sources[i], Message: fmt.Sprintf("argument %s is given more than once", name)}
+		}
+		matched[len(fn.Params)+j] = i
+	}
+	if required := len(fn.Params) - fn.Defaults; positional < required || positional > len(fn.Params) {
+		if fn.Defaults == 0 {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("expected %d arguments, got %d", len(fn.Params), positional)}
+		}
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("expected %d to %d arguments, got %d", required, len(fn.Params), positional)}
+	}
+	for j, param := range fn.Named {
+		if !param.Optional && matched[len(fn.Params)+j] < 0 {
+			return nil, utils.Error{Source: source, Message: "missing argument " + param.Name}
+		}
+	}
+	return matched, nil
+}
+
+func namedParamIndex(fn env.FunctionType, name string) int {
+	for i, param := range fn.Named {
+		if param.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParamTypes returns the type of the parameter of fn given each argument, nil where it has none.
+// offset is the number of positional arguments passed before them, see ArgumentTypes.
+func ParamTypes(fn env.FunctionType, names []string, offset int) []env.Type {
+	types := make([]env.Type, len(names))
+	positional := offset
+	for i, name := range names {
+		if name != "" {
+			if j := namedParamIndex(fn, name); j >= 0 {
+				types[i] = fn.Named[j].Type
+			}
+			continue
+		}
+		if positional < len(fn.Params) {
+			types[i] = fn.Params[positional]
+		}
+		positional++
+	}
+	return types
+}
+
+// paramArgs returns the argument given to each of params, nil where omitted, see MatchArgs
+func paramArgs(params []env.Param, args []Argument, source utils.String) ([]*Argument, error) {
+	fnType := env.NewFunctionType(nil, params, nil)
+	names, sources := make([]string, len(args)), make([]utils.String, len(args))
+	for i, arg := range args {
+		names[i], sources[i] = arg.Name, arg.Source
+	}
+	matched, err := MatchArgs(fnType, names, sources, source)
+	if err != nil {
+		return nil, err
+	}
+	given := make([]*Argument, len(params))
+	positional, named := 0, len(fnType.Params)
+	for i, param := range params {
+		var k int
+		if param.Named {
+			k, named = matched[named], named+1
+		} else {
+			k, positional = matched[positional], positional+1
+		}
+		if k >= 0 {
+			given[i] = &args[k]
+		}
+	}
+	return given, nil
+}
+
+// paramValue returns the argument of a parameter of type t, its default value evaluated in the
+// environment of the call, or none, when the argument is omitted
+func paramValue(callEnv *env.Env, param env.Param, t env.Type, given *Argument, source utils.String) (Argument, error) {
+	if given != nil {
+		return *given, nil
+	}
+	if param.Default == nil {
+		return Argument{Name: param.Name, Value: env.NewNoneValue(source), Source: source}, nil
+	}
+	value, err := evalBody(callEnv, param.Default, t)
+	if err != nil {
+		return Argument{}, err
+	}
+	if value == nil {
+		return Argument{}, utils.Error{Source: param.Default.Source(), Message: "expression has no value"}
+	}
+	return Argument{Name: param.Name, Value: value, Source: param.Default.Source()}, nil
+}
+
+type ReturnExpr struct {
+	source utils.String
+	Value  Expr // nil for a bare `ret`
+}
+
+func NewReturnExpr(value Expr, source utils.String) ReturnExpr {
+	return ReturnExpr{source: source, Value: value}
+}
+
+func (r ReturnExpr) Source() utils.String {
+	return r.source
+}
+
+func (r ReturnExpr) Eval(e *env.Env) (env.Value, error) {
+	if r.Value == nil {
+		return nil, returnSignal{source: r.source}
+	}
+	value, err := r.Value.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	return nil, returnSignal{source: r.source, value: value, expr: r.Value}
+}
+
+// returnSignal unwinds evaluation up to the enclosing function call,
+// it only surfaces as an error when `ret` is used outside of a function
+type returnSignal struct {
+	source utils.String
+	value  env.Value
+	expr   Expr // the returned expression, a literal in it takes the return type of the function
+}
+
+func (r returnSignal) Error() string {
+	return utils.Error{Source: r.source, Message: "ret outside of a function"}.Error()
+}
+
+func typeNameOf(value env.Value) string {
+	if value == nil {
+		return "nothing"
+	}
+	return value.Type().Name()
+}
@@ -0,0 +1,27 @@
+package ast_test
+
+import (
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+func TestRecursionTooDeep(t *testing.T) {
+	tests := []string{
+		"fn f() -> f()\nf()",
+		"fn f(n: i32): i32 -> f(n + 1) + 1\nf(0)",
+		"fn even(n: i32): bool -> not odd(n)\nfn odd(n: i32): bool -> not even(n)\neven(1)",
+		"f := fn () { g := fn () { f() }\ng() }\nf()",
+	}
+	for _, source := range tests {
+		_, err := evalProgram(t, source)
+		if e, ok := err.(utils.Error); !ok || e.Message != "recursion too deep" {
+			t.Errorf("evaluating %q gives %v, want recursion too deep", source, err)
+		}
+	}
+	// the calls that failed no longer count
+	value, err := evalProgram(t, "fn count(n: i32): i32 -> if n == 0 { 0 } else { 1 + count(n - 1) }\ncount(5000)")
+	if err != nil || value.String() != "5000" {
+		t.Errorf("a recursion 5000 calls deep gives %v, %v, want 5000", value, err)
+	}
+}
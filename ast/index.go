@@ -0,0 +1,120 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// IndexExpr reads `target[index]`, or a slice of target when index is a range
+type IndexExpr struct {
+	source utils.String
+	Target Expr
+	Index  Expr
+}
+
+func NewIndexExpr(target Expr, index Expr, source utils.String) IndexExpr {
+	return IndexExpr{source: source, Target: target, Index: index}
+}
+
+func (i IndexExpr) Source() utils.String {
+	return i.source
+}
+
+func (i IndexExpr) Eval(e *env.Env) (env.Value, error) {
+	target, err := i.Target.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.Index.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	return IndexValue(target, index, i.Index.Source(), i.source)
+}
+
+// IndexValue reads target[index], indexSource is used to report out of range indices
+func IndexValue(target, index env.Value, indexSource utils.String, source utils.String) (env.Value, error) {
+	switch t := target.(type) {
+	case *env.RangeValue:
+		length, ok := t.Len()
+		if !ok {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot index %s, it has %s elements", t, t.Count())}
+		}
+		if slice, ok := index.(*env.RangeValue); ok {
+			from, to, by, err := sliceBounds(slice, length, indexSource)
+			if err != nil {
+				return nil, err
+			}
+			return t.Slice(from, to, by, source), nil
+		}
+		i, err := indexBounds(index, length, indexSource)
+		if err != nil {
+			return nil, err
+		}
+		return env.NewIntValue(t.Elem, t.At(i), source), nil
+	case *env.ListValue:
+		if slice, ok := index.(*env.RangeValue); ok {
+			from, to, by, err := sliceBounds(slice, t.Len(), indexSource)
+			if err != nil {
+				return nil, err
+			}
+			return t.Slice(from, to, by, source), nil
+		}
+		i, err := indexBounds(index, t.Len(), indexSource)
+		if err != nil {
+			return nil, err
+		}
+		return t.Items[i], nil
+	case env.BaseValue[string]:
+		// strings are indexed by char, not by byte
+		chars := []rune(t.GetValue())
+		if slice, ok := index.(*env.RangeValue); ok {
+			from, to, by, err := sliceBounds(slice, int64(len(chars)), indexSource)
+			if err != nil {
+				return nil, err
+			}
+			var b strings.Builder
+			for i := from; i < to; i += by {
+				b.WriteRune(chars[i])
+			}
+			return env.NewStrValue(b.String(), source), nil
+		}
+		i, err := indexBounds(index, int64(len(chars)), indexSource)
+		if err != nil {
+			return nil, err
+		}
+		return env.NewCharValue(chars[i], source), nil
+	}
+	return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot index a value of type %s", typeNameOf(target))}
+}
+
+// indexBounds checks that index is an integer within 0..length
+func indexBounds(index env.Value, length int64, source utils.String) (int64, error) {
+	i, ok := env.BigIntValue(index)
+	if !ok {
+		return 0, utils.Error{Source: source, Message: fmt.Sprintf("index must be an integer, got %s", typeNameOf(index))}
+	}
+	if !i.IsInt64() || i.Int64() < 0 || i.Int64() >= length {
+		return 0, utils.Error{Source: source, Message: fmt.Sprintf("index %s out of range for length %d", i, length)}
+	}
+	return i.Int64(), nil
+}
+
+// sliceBounds turns a slicing range into the from (included) and to (excluded)
+// indices and the step, checking them against length
+func sliceBounds(slice *env.RangeValue, length int64, source utils.String) (int64, int64, int64, error) {
+	from, to := slice.Start, slice.End
+	if slice.Inclusive {
+		to++
+	}
+	if slice.Step < 0 {
+		return 0, 0, 0, utils.Error{Source: source, Message: "slice step must be positive"}
+	}
+	if from < 0 || to > length || from > to {
+		return 0, 0, 0, utils.Error{Source: source, Message: fmt.Sprintf("slice %s out of range for length %d", slice, length)}
+	}
+	return from, to, slice.Step, nil
+}
@@ -0,0 +1,48 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// ListExpr is a list literal `[a, b, c]`, every element must have the type of the first one
+type ListExpr struct {
+	source utils.String
+	Items  []Expr
+}
+
+func NewListExpr(items []Expr, source utils.String) ListExpr {
+	return ListExpr{source: source, Items: items}
+}
+
+func (l ListExpr) Source() utils.String {
+	return l.source
+}
+
+func (l ListExpr) Eval(e *env.Env) (env.Value, error) {
+	return l.evalItems(e, nil)
+}
+
+// evalItems builds the list, elem is the expected element type or nil to use the type of
+// the first element, untyped number literals are given that type
+func (l ListExpr) evalItems(e *env.Env, elem env.Type) (env.Value, error) {
+	items := make([]env.Value, len(l.Items))
+	for i, item := range l.Items {
+		value, err := evalAs(e, item, elem)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, utils.Error{Source: item.Source(), Message: "expression has no value"}
+		}
+		if elem == nil {
+			elem = env.TypeOf(value)
+		} else if !env.SameType(value.Type(), elem) {
+			return nil, utils.Error{Source: item.Source(), Message: fmt.Sprintf("list element has type %s, expected %s", value.Type().Name(), elem.Name())}
+		}
+		items[i] = value
+	}
+	return env.NewListValue(elem, items, l.source), nil
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"fmt"
 	"strings"
 
 	"com.loop.anonx3247/env"
@@ -30,20 +31,12 @@ func (l Literal) CheckDepth(startDepth int) (int, error) {
 func LiteralFromToken(tok lexer.Token) (Literal, error) {
 	switch tok.Type {
 	case lexer.NUMBER_LITERAL:
-		if strings.Contains(tok.Value.String(), ".") || strings.Contains(tok.Value.String(), "e") {
-			val, err := env.TryFloatFrom[float32](tok)
-			if err != nil {
-				return Literal{}, err
-			}
-			return Literal{Value: val}, nil
-		} else {
-			val, err := env.TryIntFrom[int32](tok)
-			if err != nil {
-				return Literal{}, err
-			}
-			return Literal{Value: val}, nil
+		val, err := env.NumberLiteral(tok.Value.String(), tok.Value)
+		if err != nil {
+			return Literal{}, err
 		}
-	case lexer.STRING_LITERAL:
+		return Literal{Value: val}, nil
+	case lexer.STRING_LITERAL, lexer.STRING_HEAD, lexer.STRING_MIDDLE, lexer.STRING_TAIL:
 		val, err := env.TryStrFrom(tok)
 		if err != nil {
 			return Literal{}, err
@@ -55,6 +48,8 @@ func LiteralFromToken(tok lexer.Token) (Literal, error) {
 			return Literal{}, err
 		}
 		return Literal{Value: val}, nil
+	case lexer.NONE:
+		return Literal{Value: env.NewNoneValue(tok.Value)}, nil
 	}
 	return Literal{}, tok.Error("invalid literal")
 }
@@ -62,3 +57,113 @@ func LiteralFromToken(tok lexer.Token) (Literal, error) {
 func NewLiteral(value env.Value) Literal {
 	return Literal{Value: value}
 }
+
+// CoerceLiteral gives a number literal the numeric type t it is used as, as in `x : u8 = 200`, and
+// turns a string literal into a char where a char is expected, as in `c : char = 'a'`. ok is false
+// when expr is not such a literal or t is neither numeric nor char, the value is then left as is.
+// A literal used as a T? is given type T.
+func CoerceLiteral(expr Expr, t env.Type) (value env.Value, ok bool, err error) {
+	t = env.Unwrap(t)
+	if t != nil && t.BaseType() == env.Char {
+		return charLiteral(expr)
+	}
+	text, ok := numberLiteralText(expr)
+	if !ok || t == nil || t.BaseType() > env.F64 {
+		return nil, false, nil
+	}
+	value, err = env.NumberFromLiteral(text, t.BaseType(), expr.Source())
+	return value, true, err
+}
+
+// charLiteral reads a string literal holding a single char as a char
+func charLiteral(expr Expr) (env.Value, bool, error) {
+	if !isStringLiteral(expr) {
+		return nil, false, nil
+	}
+	chars := []rune(expr.(Literal).Value.(env.BaseValue[string]).GetValue())
+	if len(chars) != 1 {
+		return nil, true, utils.Error{Source: expr.Source(), Message: fmt.Sprintf("a char holds a single character, got %d", len(chars))}
+	}
+	return env.NewCharValue(chars[0], expr.Source()), true, nil
+}
+
+func isStringLiteral(expr Expr) bool {
+	if lit, ok := expr.(Literal); ok {
+		_, ok = lit.Value.(env.BaseValue[string])
+		return ok
+	}
+	return false
+}
+
+// evalAs evaluates expr where a value of type t is expected, t is nil when nothing is expected.
+// Untyped number literals are given type t, including those in list and tuple literals, the operands
+// of arithmetic and those a block or a conditional ends with. Where a T? is expected they are given T.
+func evalAs(e *env.Env, expr Expr, t env.Type) (env.Value, error) {
+	t = env.Unwrap(t)
+	if value, ok, err := CoerceLiteral(expr, t); ok {
+		return value, err
+	}
+	switch x := expr.(type) {
+	case ParenExpr:
+		return evalAs(e, x.Expr, t)
+	case ListExpr:
+		if list, ok := t.(env.ListType); ok {
+			return x.evalItems(e, list.Elem)
+		}
+	case TupleExpr:
+		if tuple, ok := t.(env.TupleType); ok && len(tuple.Elems) == len(x.Items) {
+			return x.evalItems(e, tuple.Elems)
+		}
+	case *Scope:
+		return x.evalInAs(e.NewChild(), t)
+	case ConditionalExpr:
+		return x.evalAs(e, t)
+	case *BinaryExpr:
+		return x.evalAs(e, t)
+	case BinaryExpr:
+		return x.evalAs(e, t)
+	}
+	return expr.Eval(e)
+}
+
+// TypedFromRight reports whether the left operand of a binary expression takes its type from the
+// right one: an untyped literal next to a typed operand, an integer literal next to a float literal,
+// or a string literal which may be a char, as in `'a' == c`
+func TypedFromRight(left, right Expr) bool {
+	if isStringLiteral(left) {
+		return !isStringLiteral(right)
+	}
+	leftText, leftUntyped := numberLiteralText(left)
+	rightText, rightUntyped := numberLiteralText(right)
+	if !rightUntyped {
+		return leftUntyped
+	}
+	return leftUntyped && !env.IsFloatLiteral(leftText) && env.IsFloatLiteral(rightText)
+}
+
+// numberLiteralText returns the text of a possibly negated or parenthesised number literal
+func numberLiteralText(expr Expr) (string, bool) {
+	switch e := expr.(type) {
+	case Literal:
+		if e.Value.Type().BaseType() > env.F64 {
+			return "", false
+		}
+		// literals with a suffix already have their type
+		if _, _, typed := env.SplitNumberLiteral(e.Source().String()); typed {
+			return "", false
+		}
+		return e.Source().String(), true
+	case ParenExpr:
+		return numberLiteralText(e.Expr)
+	case UnaryExpr:
+		if e.Op != lexer.MINUS {
+			return "", false
+		}
+		text, ok := numberLiteralText(e.Value)
+		if !ok || strings.HasPrefix(text, "-") {
+			return "", false
+		}
+		return "-" + text, true
+	}
+	return "", false
+}
@@ -0,0 +1,182 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// WhileExpr runs its body for as long as its condition holds
+type WhileExpr struct {
+	source    utils.String
+	Condition Expr
+	Body      Scope
+}
+
+func NewWhileExpr(condition Expr, body Scope, source utils.String) WhileExpr {
+	return WhileExpr{source: source, Condition: condition, Body: body}
+}
+
+func (w WhileExpr) Source() utils.String {
+	return w.source
+}
+
+func (w WhileExpr) Eval(e *env.Env) (env.Value, error) {
+	for {
+		condition, err := w.Condition.Eval(e)
+		if err != nil {
+			return nil, err
+		}
+		conditionValue, ok := condition.(env.BaseValue[bool])
+		if !ok {
+			return nil, utils.Error{Source: w.Condition.Source(), Message: "condition is not a boolean"}
+		}
+		if !conditionValue.GetValue() {
+			return nil, nil
+		}
+		stop, _, err := runLoopBody(&w.Body, e, false)
+		if stop || err != nil {
+			return nil, err
+		}
+	}
+}
+
+// LoopExpr runs its body until a break, its value is the one given to break
+type LoopExpr struct {
+	source utils.String
+	Body   Scope
+}
+
+func NewLoopExpr(body Scope, source utils.String) LoopExpr {
+	return LoopExpr{source: source, Body: body}
+}
+
+func (l LoopExpr) Source() utils.String {
+	return l.source
+}
+
+func (l LoopExpr) Eval(e *env.Env) (env.Value, error) {
+	for {
+		stop, value, err := runLoopBody(&l.Body, e, true)
+		if stop || err != nil {
+			return value, err
+		}
+	}
+}
+
+// ForExpr binds each element of an iterable to Pattern and runs its body
+type ForExpr struct {
+	source   utils.String
+	Pattern  Pattern
+	Iterable Expr
+	Body     Scope
+}
+
+func NewForExpr(pattern Pattern, iterable Expr, body Scope, source utils.String) ForExpr {
+	return ForExpr{source: source, Pattern: pattern, Iterable: iterable, Body: body}
+}
+
+func (f ForExpr) Source() utils.String {
+	return f.source
+}
+
+func (f ForExpr) Eval(e *env.Env) (env.Value, error) {
+	value, err := f.Iterable.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	iterator, ok := env.IteratorOf(value)
+	if !ok {
+		return nil, utils.Error{Source: f.Iterable.Source(), Message: fmt.Sprintf("cannot iterate over a value of type %s", typeNameOf(value))}
+	}
+	for {
+		item, ok := iterator.Next()
+		if !ok {
+			return nil, nil
+		}
+		iterationEnv := e.NewChild()
+		if err := f.Pattern.Bind(iterationEnv, item, true); err != nil {
+			return nil, err
+		}
+		stop, _, err := runLoopBody(&f.Body, iterationEnv, false)
+		if stop || err != nil {
+			return nil, err
+		}
+	}
+}
+
+// runLoopBody evaluates one iteration and reports whether the loop should stop,
+// along with the value carried by break if allowValue is set
+func runLoopBody(body *Scope, e *env.Env, allowValue bool) (bool, env.Value, error) {
+	_, err := body.Eval(e)
+	switch signal := err.(type) {
+	case nil, continueSignal:
+		return false, nil, nil
+	case breakSignal:
+		if signal.value != nil && !allowValue {
+			return true, nil, utils.Error{Source: signal.source, Message: "break with a value is only allowed in loop"}
+		}
+		return true, signal.value, nil
+	}
+	return true, nil, err
+}
+
+type BreakExpr struct {
+	source utils.String
+	Value  Expr // nil for a bare `break`
+}
+
+func NewBreakExpr(value Expr, source utils.String) BreakExpr {
+	return BreakExpr{source: source, Value: value}
+}
+
+func (b BreakExpr) Source() utils.String {
+	return b.source
+}
+
+func (b BreakExpr) Eval(e *env.Env) (env.Value, error) {
+	if b.Value == nil {
+		return nil, breakSignal{source: b.source}
+	}
+	value, err := b.Value.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	return nil, breakSignal{source: b.source, value: value}
+}
+
+type ContinueExpr struct {
+	source utils.String
+}
+
+func NewContinueExpr(source utils.String) ContinueExpr {
+	return ContinueExpr{source: source}
+}
+
+func (c ContinueExpr) Source() utils.String {
+	return c.source
+}
+
+func (c ContinueExpr) Eval(e *env.Env) (env.Value, error) {
+	return nil, continueSignal{source: c.source}
+}
+
+// breakSignal and continueSignal unwind evaluation up to the enclosing loop,
+// like returnSignal they only surface as errors when used outside of one
+type breakSignal struct {
+	source utils.String
+	value  env.Value
+}
+
+func (b breakSignal) Error() string {
+	return utils.Error{Source: b.source, Message: "break outside of a loop"}.Error()
+}
+
+type continueSignal struct {
+	source utils.String
+}
+
+func (c continueSignal) Error() string {
+	return utils.Error{Source: c.source, Message: "continue outside of a loop"}.Error()
+}
@@ -0,0 +1,403 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+type MatchPatternKind int
+
+const (
+	WildcardPattern MatchPatternKind = iota // `_`
+	BindingPattern                          // `x` or `x: u8`
+	VariantPattern                          // `Black`, `Color.Black` or `Rgb(r, g, b)`
+	TuplePattern                            // `(r: u8, g: u8, b: u8)`
+	LiteralPattern                          // `0`, `'text'`, `true`, `none`
+)
+
+// MatchPattern is the left-hand side of a match arm
+type MatchPattern struct {
+	source  utils.String
+	Kind    MatchPatternKind
+	Name    string   // bound name or variant name
+	Enum    string   // enum qualifying a variant, `Color` in `Color.Black`
+	Type    env.Type // type annotation of a binding, nil when absent
+	Elems   []MatchPattern
+	Literal env.Value
+}
+
+func NewWildcardPattern(source utils.String) MatchPattern {
+	return MatchPattern{source: source, Kind: WildcardPattern}
+}
+
+func NewBindingPattern(name string, t env.Type, source utils.String) MatchPattern {
+	return MatchPattern{source: source, Kind: BindingPattern, Name: name, Type: t}
+}
+
+// NewVariantPattern builds a variant pattern, enum is empty when the variant
+// is not qualified and elems is nil for unit variants
+func NewVariantPattern(enum, name string, elems []MatchPattern, source utils.String) MatchPattern {
+	return MatchPattern{source: source, Kind: VariantPattern, Enum: enum, Name: name, Elems: elems}
+}
+
+func NewTupleMatchPattern(elems []MatchPattern, source utils.String) MatchPattern {
+	return MatchPattern{source: source, Kind: TuplePattern, Elems: elems}
+}
+
+func NewLiteralPattern(value env.Value, source utils.String) MatchPattern {
+	return MatchPattern{source: source, Kind: LiteralPattern, Literal: value}
+}
+
+func (p MatchPattern) Source() utils.String {
+	return p.source
+}
+
+// IsNone reports whether the pattern is `none`
+func (p MatchPattern) IsNone() bool {
+	return p.Kind == LiteralPattern && env.IsNone(p.Literal)
+}
+
+// Match reports whether value matches the pattern, binding names into e when it does
+func (p MatchPattern) Match(e *env.Env, value env.Value) (bool, error) {
+	switch p.Kind {
+	case WildcardPattern:
+		return true, nil
+	case BindingPattern:
+		// bindings unwrap optionals, `none` has to be matched on its own
+		if env.IsNone(value) || (p.Type != nil && !env.SameType(value.Type(), p.Type)) {
+			return false, nil
+		}
+		e.Set(p.Name, value, true)
+		return true, nil
+	case LiteralPattern:
+		literal := p.literalAs(value.Type())
+		if !env.SameType(value.Type(), literal.Type()) {
+			return false, nil
+		}
+		equal, err := EqualsValues(value, literal, p.source)
+		if err != nil {
+			return false, err
+		}
+		return equal.(env.BaseValue[bool]).GetValue(), nil
+	case VariantPattern:
+		enum, ok := value.(*env.EnumValue)
+		if !ok || enum.EnumType.Variants[enum.Variant].Name != p.Name {
+			return false, nil
+		}
+		return matchAll(e, p.Elems, enum.Payload)
+	case TuplePattern:
+		switch t := value.(type) {
+		case *env.TupleValue:
+			return matchAll(e, p.Elems, t.Items)
+		case *env.EnumValue:
+			if t.EnumType.Variants[t.Variant].Name != "" {
+				return false, nil
+			}
+			return matchAll(e, p.Elems, t.Payload)
+		}
+	}
+	return false, nil
+}
+
+func matchAll(e *env.Env, patterns []MatchPattern, values []env.Value) (bool, error) {
+	if len(patterns) != len(values) {
+		return false, nil
+	}
+	for i, pattern := range patterns {
+		ok, err := pattern.Match(e, values[i])
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// irrefutable reports whether the pattern matches every value of type t
+func (p MatchPattern) irrefutable(t env.Type) bool {
+	switch p.Kind {
+	case WildcardPattern:
+		return true
+	case BindingPattern:
+		if _, ok := t.(env.OptionalType); ok {
+			return false
+		}
+		return p.Type == nil || env.SameType(t, p.Type)
+	case TuplePattern:
+		tuple, ok := t.(env.TupleType)
+		return ok && irrefutableAll(p.Elems, tuple.Elems)
+	}
+	return false
+}
+
+func irrefutableAll(patterns []MatchPattern, types []env.Type) bool {
+	if len(patterns) != len(types) {
+		return false
+	}
+	for i, pattern := range patterns {
+		if !pattern.irrefutable(types[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// literalAs is the literal of the pattern matched against a value of type t, a string literal of
+// a single char matches chars
+func (p MatchPattern) literalAs(t env.Type) env.Value {
+	if s, ok := p.Literal.(env.BaseValue[string]); ok && t != nil && t.BaseType() == env.Char && utf8.RuneCountInString(s.GetValue()) == 1 {
+		r, _ := utf8.DecodeRuneInString(s.GetValue())
+		return env.NewCharValue(r, p.source)
+	}
+	return p.Literal
+}
+
+// compatible reports whether the pattern can match some value of type t, the patterns other than
+// `none` match the T in a T?
+func (p MatchPattern) compatible(t env.Type) bool {
+	if p.IsNone() {
+		_, ok := t.(env.OptionalType)
+		return ok
+	}
+	if p.Kind != WildcardPattern {
+		t = env.Unwrap(t)
+	}
+	switch p.Kind {
+	case WildcardPattern:
+		return true
+	case BindingPattern:
+		return p.Type == nil || env.SameType(t, p.Type)
+	case LiteralPattern:
+		return env.SameType(t, p.literalAs(t).Type())
+	case TuplePattern:
+		tuple, ok := t.(env.TupleType)
+		if !ok || len(tuple.Elems) != len(p.Elems) {
+			return false
+		}
+		for i, elem := range p.Elems {
+			if !elem.compatible(tuple.Elems[i]) {
+				return false
+			}
+		}
+		return true
+	case VariantPattern:
+		enum, ok := t.(*env.EnumType)
+		if !ok || (p.Enum != "" && p.Enum != enum.Name()) {
+			return false
+		}
+		i := enum.VariantIndex(p.Name)
+		return i >= 0 && enum.Variants[i].IsUnit() == (p.Elems == nil) && compatibleAll(p.Elems, enum.Variants[i].Payload)
+	}
+	return false
+}
+
+func compatibleAll(patterns []MatchPattern, types []env.Type) bool {
+	if len(patterns) != len(types) {
+		return false
+	}
+	for i, pattern := range patterns {
+		if !pattern.compatible(types[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// variants returns the variants of enum the pattern may match,
+// and whether it matches every value of those variants
+func (p MatchPattern) variants(enum *env.EnumType) ([]int, bool) {
+	switch p.Kind {
+	case WildcardPattern, BindingPattern:
+		if !p.compatible(enum) {
+			return nil, false
+		}
+		all := make([]int, len(enum.Variants))
+		for i := range all {
+			all[i] = i
+		}
+		return all, true
+	case VariantPattern:
+		if !p.compatible(enum) {
+			return nil, false
+		}
+		i := enum.VariantIndex(p.Name)
+		return []int{i}, irrefutableAll(p.Elems, enum.Variants[i].Payload)
+	case TuplePattern:
+		matched := []int{}
+		complete := true
+		for i, variant := range enum.Variants {
+			if variant.Name == "" && compatibleAll(p.Elems, variant.Payload) {
+				matched = append(matched, i)
+				complete = complete && irrefutableAll(p.Elems, variant.Payload)
+			}
+		}
+		return matched, complete && len(matched) > 0
+	}
+	return nil, false
+}
+
+type MatchArm struct {
+	Pattern MatchPattern
+	Body    Expr
+}
+
+// MatchExpr evaluates the body of the first arm whose pattern matches the scrutinee
+type MatchExpr struct {
+	source    utils.String
+	Scrutinee Expr
+	Arms      []MatchArm
+}
+
+func NewMatchExpr(scrutinee Expr, arms []MatchArm, source utils.String) MatchExpr {
+	return MatchExpr{source: source, Scrutinee: scrutinee, Arms: arms}
+}
+
+func (m MatchExpr) Source() utils.String {
+	return m.source
+}
+
+func (m MatchExpr) Eval(e *env.Env) (env.Value, error) {
+	value, err := m.Scrutinee.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, utils.Error{Source: m.Scrutinee.Source(), Message: "expression has no value"}
+	}
+	t := env.TypeOf(value)
+	if _, ok := t.(env.OptionalType); !ok && hasNoneArm(m) {
+		t = env.OptionalType{Elem: t}
+	}
+	if err := CheckMatchArms(m, t); err != nil {
+		return nil, err
+	}
+	for _, arm := range m.Arms {
+		armEnv := e.NewChild()
+		ok, err := arm.Pattern.Match(armEnv, value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return arm.Body.Eval(armEnv)
+		}
+	}
+	return nil, utils.Error{Source: m.source, Message: fmt.Sprintf("no arm matches %s", value)}
+}
+
+// CheckMatchArms verifies that the arms of m can all be reached and
+// together cover every value of the scrutinee type t
+func CheckMatchArms(m MatchExpr, t env.Type) error {
+	if enum, ok := t.(*env.EnumType); ok {
+		return checkEnumArms(m, enum)
+	}
+	if optional, ok := t.(env.OptionalType); ok {
+		return checkOptionalArms(m, optional)
+	}
+
+	exhaustive := false
+	seenTrue, seenFalse := false, false
+	for _, arm := range m.Arms {
+		if exhaustive {
+			return utils.Error{Source: arm.Pattern.Source(), Message: "unreachable match arm"}
+		}
+		if !arm.Pattern.compatible(t) {
+			return utils.Error{Source: arm.Pattern.Source(), Message: fmt.Sprintf("pattern cannot match a value of type %s", t.Name())}
+		}
+		if arm.Pattern.irrefutable(t) {
+			exhaustive = true
+		}
+		if arm.Pattern.Kind == LiteralPattern && t.BaseType() == env.Bool {
+			b := arm.Pattern.Literal.(env.BaseValue[bool]).GetValue()
+			if (b && seenTrue) || (!b && seenFalse) {
+				return utils.Error{Source: arm.Pattern.Source(), Message: "unreachable match arm"}
+			}
+			seenTrue, seenFalse = seenTrue || b, seenFalse || !b
+			exhaustive = seenTrue && seenFalse
+		}
+	}
+	if !exhaustive {
+		if t.BaseType() == env.Bool {
+			missing := "true"
+			if seenTrue {
+				missing = "false"
+			}
+			return utils.Error{Source: m.source, Message: "non-exhaustive match, missing " + missing}
+		}
+		return utils.Error{Source: m.source, Message: "non-exhaustive match, add a `_` arm"}
+	}
+	return nil
+}
+
+// checkOptionalArms checks the arms of a match on a T?, `none` is matched by a `none` or a `_` arm
+// and the other arms have to cover every T. The T of none itself is unknown, only its arm is checked.
+func checkOptionalArms(m MatchExpr, optional env.OptionalType) error {
+	values := MatchExpr{source: m.source, Scrutinee: m.Scrutinee}
+	noneCovered := false
+	for _, arm := range m.Arms {
+		if arm.Pattern.IsNone() {
+			if noneCovered {
+				return utils.Error{Source: arm.Pattern.Source(), Message: "unreachable match arm"}
+			}
+			noneCovered = true
+			continue
+		}
+		if arm.Pattern.Kind == WildcardPattern {
+			noneCovered = true
+		}
+		values.Arms = append(values.Arms, arm)
+	}
+	if optional.Elem != nil {
+		if err := CheckMatchArms(values, optional.Elem); err != nil {
+			return err
+		}
+	}
+	if !noneCovered {
+		return utils.Error{Source: m.source, Message: "non-exhaustive match, missing none"}
+	}
+	return nil
+}
+
+// hasNoneArm reports whether one of the arms of m matches none
+func hasNoneArm(m MatchExpr) bool {
+	for _, arm := range m.Arms {
+		if arm.Pattern.IsNone() {
+			return true
+		}
+	}
+	return false
+}
+
+func checkEnumArms(m MatchExpr, enum *env.EnumType) error {
+	covered := make([]bool, len(enum.Variants))
+	for _, arm := range m.Arms {
+		matched, complete := arm.Pattern.variants(enum)
+		if len(matched) == 0 {
+			return utils.Error{Source: arm.Pattern.Source(), Message: fmt.Sprintf("pattern does not match any variant of %s", enum.Name())}
+		}
+		reachable := false
+		for _, i := range matched {
+			reachable = reachable || !covered[i]
+		}
+		if !reachable {
+			return utils.Error{Source: arm.Pattern.Source(), Message: "unreachable match arm"}
+		}
+		if complete {
+			for _, i := range matched {
+				covered[i] = true
+			}
+		}
+	}
+	missing := []string{}
+	for i, variant := range enum.Variants {
+		if !covered[i] {
+			missing = append(missing, variant.String())
+		}
+	}
+	if len(missing) > 0 {
+		return utils.Error{Source: m.source, Message: "non-exhaustive match, missing " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package ast
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// ModDecl names the module defined by a file, `mod geometry`. It has to come first in the file,
+// which is read by the loader before the file is evaluated.
+type ModDecl struct {
+	source utils.String
+	Name   string
+}
+
+func NewModDecl(name string, source utils.String) ModDecl {
+	return ModDecl{source: source, Name: name}
+}
+
+func (m ModDecl) Source() utils.String {
+	return m.source
+}
+
+func (m ModDecl) Eval(e *env.Env) (env.Value, error) {
+	return nil, nil
+}
+
+// ImportExpr imports a module by its path, `geometry.shapes` is the file geometry/shapes.lp
+//
+//	import geometry.shapes          binds the module as shapes
+//	import geometry.shapes as sh    binds it as sh
+//	use shapes                      only looks for shapes.lp next to the importing file
+//	from geometry.shapes import Square, area as square_area
+type ImportExpr struct {
+	source utils.String
+	Path   []string
+	Alias  string       // name the module is bound to, unused by `from`
+	Local  bool         // `use`
+	Names  []ImportName // declarations imported by `from`, nil otherwise
+}
+
+// ImportName is a declaration imported by `from`, bound to Alias
+type ImportName struct {
+	source utils.String
+	Name   string
+	Alias  string
+}
+
+func NewImportName(name, alias string, source utils.String) ImportName {
+	return ImportName{source: source, Name: name, Alias: alias}
+}
+
+func (n ImportName) Source() utils.String {
+	return n.source
+}
+
+func NewImportExpr(path []string, alias string, local bool, names []ImportName, source utils.String) ImportExpr {
+	return ImportExpr{source: source, Path: path, Alias: alias, Local: local, Names: names}
+}
+
+func (i ImportExpr) Source() utils.String {
+	return i.source
+}
+
+// Module returns the dotted path of the imported module
+func (i ImportExpr) Module() string {
+	return strings.Join(i.Path, ".")
+}
+
+func (i ImportExpr) Eval(e *env.Env) (env.Value, error) {
+	importer := e.Importer()
+	if importer == nil {
+		return nil, utils.Error{Source: i.source, Message: "modules cannot be imported here"}
+	}
+	module, err := importer.Import(i.Path, i.Local, i.source)
+	if err != nil {
+		return nil, err
+	}
+	if i.Names == nil {
+		e.Set(i.Alias, module, true)
+		return nil, nil
+	}
+	for _, name := range i.Names {
+		value, err := module.Member(name.Name, name.source)
+		if err != nil {
+			return nil, err
+		}
+		e.Set(name.Alias, value, true)
+	}
+	return nil, nil
+}
+
+// namedValue returns the declaration named by an annotation, `shapes.Square` is looked up in the
+// imported module shapes
+func namedValue(e *env.Env, named env.NamedType, source utils.String) (env.Value, error) {
+	if named.Module == "" {
+		value, _ := e.Get(named.TypeName)
+		return value, nil
+	}
+	value, _ := e.Get(named.Module)
+	module, ok := value.(*env.ModuleValue)
+	if !ok {
+		return nil, utils.Error{Source: source, Message: named.Module + " is not a module"}
+	}
+	return module.Member(named.TypeName, source)
+}
+
+// PubDecl makes a top level declaration of a module public, `pub fn area(s: Square) : f32`
+type PubDecl struct {
+	source utils.String
+	Decl   Expr
+}
+
+func NewPubDecl(decl Expr, source utils.String) PubDecl {
+	return PubDecl{source: source, Decl: decl}
+}
+
+func (p PubDecl) Source() utils.String {
+	return p.source
+}
+
+func (p PubDecl) Eval(e *env.Env) (env.Value, error) {
+	value, err := p.Decl.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := DeclaredName(p.Decl)
+	e.Export(name)
+	return value, nil
+}
+
+// DeclaredName returns the name declared by a function, comp, enum, trait or variable declaration
+func DeclaredName(expr Expr) (string, bool) {
+	switch n := expr.(type) {
+	case FunctionDecl:
+		return n.Name, n.Name != ""
+	case CompDecl:
+		return n.Name, true
+	case EnumDecl:
+		return n.Name, true
+	case AbsDecl:
+		return n.Name, true
+	case AssignmentExpr:
+		return n.Name, n.Kind == DECLARATION
+	}
+	return "", false
+}
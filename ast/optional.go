@@ -0,0 +1,32 @@
+package ast
+
+import (
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// IsNoneExpr tests an optional, `x is none` or `x is not none`
+type IsNoneExpr struct {
+	source  utils.String
+	Value   Expr
+	Negated bool // `is not none`
+}
+
+func NewIsNoneExpr(value Expr, negated bool, source utils.String) IsNoneExpr {
+	return IsNoneExpr{source: source, Value: value, Negated: negated}
+}
+
+func (i IsNoneExpr) Source() utils.String {
+	return i.source
+}
+
+func (i IsNoneExpr) Eval(e *env.Env) (env.Value, error) {
+	value, err := i.Value.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, utils.Error{Source: i.Value.Source(), Message: "expression has no value"}
+	}
+	return env.NewBoolValue(env.IsNone(value) != i.Negated, i.source), nil
+}
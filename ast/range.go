@@ -0,0 +1,63 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// RangeValues builds `left..right`, or adds a step when left is already a range (`0..10..2`)
+func RangeValues(left, right env.Value, inclusive bool, source utils.String) (env.Value, error) {
+	if r, ok := left.(*env.RangeValue); ok && !inclusive {
+		step, ok := env.IntValue(right)
+		if !ok || right.Type().BaseType() != r.Elem {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("range step must be %s, got %s", r.Elem.Name(), typeNameOf(right))}
+		}
+		if step < 0 && r.Elem == env.U64 {
+			// a u64 above the largest i64 reads as negative, see env.IntValue
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("range step %s is too large", right)}
+		}
+		return r.WithStep(step, source)
+	}
+
+	start, ok := env.IntValue(left)
+	if !ok {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("range bounds must be integers, got %s", typeNameOf(left))}
+	}
+	end, ok := env.IntValue(right)
+	if !ok {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("range bounds must be integers, got %s", typeNameOf(right))}
+	}
+	if left.Type().BaseType() != right.Type().BaseType() {
+		return nil, utils.Error{Source: source, Message: "type mismatch in range"}
+	}
+	return env.NewRangeValue(left.Type().BaseType(), start, end, inclusive, source), nil
+}
+
+// InValues tests whether left is an element of right
+func InValues(left, right env.Value, source utils.String) (env.Value, error) {
+	switch container := right.(type) {
+	case *env.RangeValue:
+		n, ok := env.IntValue(left)
+		if !ok || left.Type().BaseType() != container.Elem {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("type mismatch in membership test, %s in %s", typeNameOf(left), container.Type().Name())}
+		}
+		return env.NewBoolValue(container.Contains(n), source), nil
+	case *env.ListValue:
+		if !env.SameType(left.Type(), container.Elem) {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("type mismatch in membership test, %s in %s", typeNameOf(left), container.Type().Name())}
+		}
+		for _, item := range container.Items {
+			equal, err := EqualsValues(left, item, source)
+			if err != nil {
+				return nil, err
+			}
+			if equal.(env.BaseValue[bool]).GetValue() {
+				return env.NewBoolValue(true, source), nil
+			}
+		}
+		return env.NewBoolValue(false, source), nil
+	}
+	return nil, utils.Error{Source: source, Message: fmt.Sprintf("unsupported types for membership test, %s in %s", typeNameOf(left), typeNameOf(right))}
+}
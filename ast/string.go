@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// InterpolatedString is a string with `{expr}` parts, `'Hello {name}!'`. Parts are string literals
+// for the text and the interpolated expressions, whose values are written with env.Value.String().
+type InterpolatedString struct {
+	source utils.String
+	Parts  []Expr
+}
+
+func NewInterpolatedString(parts []Expr, source utils.String) InterpolatedString {
+	return InterpolatedString{source: source, Parts: parts}
+}
+
+func (s InterpolatedString) Source() utils.String {
+	return s.source
+}
+
+func (s InterpolatedString) Eval(e *env.Env) (env.Value, error) {
+	var b strings.Builder
+	for _, part := range s.Parts {
+		value, err := part.Eval(e)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, utils.Error{Source: part.Source(), Message: "expression has no value"}
+		}
+		b.WriteString(value.String())
+	}
+	return env.NewStrValue(b.String(), s.source), nil
+}
@@ -0,0 +1,57 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// CompDecl declares a comp (struct) type
+type CompDecl struct {
+	source utils.String
+	Name   string
+	Fields []env.Field
+}
+
+func NewCompDecl(name string, fields []env.Field, source utils.String) CompDecl {
+	return CompDecl{source: source, Name: name, Fields: fields}
+}
+
+func (c CompDecl) Source() utils.String {
+	return c.source
+}
+
+func (c CompDecl) Eval(e *env.Env) (env.Value, error) {
+	structType := env.NewStructType(c.Name, c.Fields, c.source)
+	e.Set(c.Name, structType, true)
+	return structType, nil
+}
+
+// constructStruct builds an instance from named arguments, every field has to be given exactly once
+func constructStruct(structType *env.StructType, args []Argument, source utils.String) (env.Value, error) {
+	fields := make([]env.Value, len(structType.Fields))
+	for _, arg := range args {
+		if arg.Name == "" {
+			return nil, utils.Error{Source: arg.Source, Message: fmt.Sprintf("fields of %s must be named, as in %s(field: value)", structType.Name(), structType.Name())}
+		}
+		i := structType.FieldIndex(arg.Name)
+		if i < 0 {
+			return nil, utils.Error{Source: arg.Source, Message: fmt.Sprintf("%s has no field %s", structType.Name(), arg.Name)}
+		}
+		if fields[i] != nil {
+			return nil, utils.Error{Source: arg.Source, Message: fmt.Sprintf("field %s is given more than once", arg.Name)}
+		}
+		field := structType.Fields[i]
+		if !env.SameType(arg.Value.Type(), field.Type) {
+			return nil, utils.Error{Source: arg.Source, Message: fmt.Sprintf("field %s expects %s, got %s", field.Name, field.Type.Name(), arg.Value.Type().Name())}
+		}
+		fields[i] = arg.Value
+	}
+	for i, field := range structType.Fields {
+		if fields[i] == nil {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("missing field %s of %s", field.Name, structType.Name())}
+		}
+	}
+	return env.NewStructValue(structType, fields, source), nil
+}
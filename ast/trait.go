@@ -0,0 +1,200 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// AbsDecl declares a trait, the methods and constants its implementations must provide
+type AbsDecl struct {
+	source    utils.String
+	Name      string
+	Methods   []env.Field
+	Constants []env.Field
+}
+
+func NewAbsDecl(name string, methods, constants []env.Field, source utils.String) AbsDecl {
+	return AbsDecl{source: source, Name: name, Methods: methods, Constants: constants}
+}
+
+func (a AbsDecl) Source() utils.String {
+	return a.source
+}
+
+func (a AbsDecl) Eval(e *env.Env) (env.Value, error) {
+	trait := env.NewTraitType(a.Name, a.Methods, a.Constants, a.source)
+	e.Set(a.Name, trait, true)
+	return trait, nil
+}
+
+// ImplDecl implements a trait for a comp, an enum or a base type
+type ImplDecl struct {
+	source    utils.String
+	Trait     string
+	For       env.Type
+	Methods   []FunctionDecl
+	Constants []AssignmentExpr
+}
+
+func NewImplDecl(trait string, forType env.Type, methods []FunctionDecl, constants []AssignmentExpr, source utils.String) ImplDecl {
+	return ImplDecl{source: source, Trait: trait, For: forType, Methods: methods, Constants: constants}
+}
+
+func (d ImplDecl) Source() utils.String {
+	return d.source
+}
+
+func (d ImplDecl) Eval(e *env.Env) (env.Value, error) {
+	value, _ := e.Get(d.Trait)
+	trait, ok := value.(*env.TraitType)
+	if !ok {
+		return nil, utils.Error{Source: d.source, Message: "unknown trait " + d.Trait}
+	}
+	forType := d.For
+	if named, ok := d.For.(env.NamedType); ok {
+		value, err := namedValue(e, named, d.source)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := value.(env.Type); ok {
+			forType = t
+		}
+	}
+	forType, err := ImplementingType(forType, trait, d.source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Self names the implementing type in the methods, as a type argument does in a generic function
+	implEnv := e.NewChild()
+	implEnv.Set(env.Self.TypeName, env.NewTypeArgValue(env.TypeParam{Name: env.Self.TypeName}, forType, []*env.TraitType{trait}, d.source), true)
+	impl := &env.Impl{Trait: trait, For: forType, Methods: map[string]env.Value{}, Constants: map[string]env.Value{}}
+	for _, method := range d.Methods {
+		required, ok := trait.MethodType(method.Name, forType)
+		if !ok {
+			return nil, utils.Error{Source: method.Source(), Message: fmt.Sprintf("%s is not a method of %s", method.Name, trait.TraitName)}
+		}
+		if params := env.NewFunctionType(nil, method.Params, nil).Params; len(params) != len(required.Params) {
+			return nil, utils.Error{Source: method.Source(), Message: fmt.Sprintf("%s of %s expects %d parameters, got %d", method.Name, trait.TraitName, len(required.Params), len(params))}
+		}
+		method = method.WithSelf(forType)
+		impl.Methods[method.Name] = env.NewFunctionValue(method.Name, method.TypeParams, method.Params, method.Return, method.Body, implEnv, method.source)
+	}
+	for _, constant := range d.Constants {
+		t, ok := trait.ConstantType(constant.Name, forType)
+		if !ok {
+			return nil, utils.Error{Source: constant.Source(), Message: fmt.Sprintf("%s is not a constant of %s", constant.Name, trait.TraitName)}
+		}
+		value, err := evalAssigned(implEnv, constant.Value, t)
+		if err != nil {
+			return nil, err
+		}
+		if !env.SameType(value.Type(), t) {
+			return nil, utils.Error{Source: constant.Value.Source(), Message: fmt.Sprintf("expected %s for %s, got %s", t.Name(), constant.Name, value.Type().Name())}
+		}
+		impl.Constants[constant.Name] = value
+	}
+	if err := MissingMembers(trait, impl.Methods, impl.Constants, d.source); err != nil {
+		return nil, err
+	}
+	if !env.Implement(impl) {
+		return nil, utils.Error{Source: d.source, Message: fmt.Sprintf("%s already implements %s", forType.Name(), trait.TraitName)}
+	}
+	return nil, nil
+}
+
+// WithSelf gives the method of an impl the implementing type t wherever it mentions Self
+func (f FunctionDecl) WithSelf(t env.Type) FunctionDecl {
+	params := make([]env.Param, len(f.Params))
+	for i, param := range f.Params {
+		param.Type = env.WithSelf(param.Type, t)
+		params[i] = param
+	}
+	f.Params = params
+	f.Return = env.WithSelf(f.Return, t)
+	return f
+}
+
+// ImplementingType checks the resolved type t of `impl Trait for Type`: only comps, enums and
+// base types have impls, and the built-in traits are only implemented for comps and enums
+func ImplementingType(t env.Type, trait *env.TraitType, source utils.String) (env.Type, error) {
+	switch t.(type) {
+	case *env.StructType, *env.EnumType:
+		return t, nil
+	case env.BaseType:
+		if trait.IsBuiltin() {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("the built-in trait %s can only be implemented for comps and enums", trait.TraitName)}
+		}
+		if t.BaseType() <= env.Char {
+			return t, nil
+		}
+	}
+	return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot implement %s for %s, only for comps, enums and base types", trait.TraitName, t.Name())}
+}
+
+// MissingMembers reports the first method or constant of trait an impl does not provide
+func MissingMembers[M, C any](trait *env.TraitType, methods map[string]M, constants map[string]C, source utils.String) error {
+	for _, method := range trait.Methods {
+		if _, ok := methods[method.Name]; !ok {
+			return utils.Error{Source: source, Message: fmt.Sprintf("missing method %s of %s", method.Name, trait.TraitName)}
+		}
+	}
+	for _, constant := range trait.Constants {
+		if _, ok := constants[constant.Name]; !ok {
+			return utils.Error{Source: source, Message: fmt.Sprintf("missing constant %s of %s", constant.Name, trait.TraitName)}
+		}
+	}
+	return nil
+}
+
+// OperatorTraits maps the operators comps and enums can overload to the built-in trait providing
+// them, `a + b` calls the add method of the Addition impl of a
+var OperatorTraits = map[lexer.TokenType]string{
+	lexer.PLUS:                  "Addition",
+	lexer.MINUS:                 "Subtraction",
+	lexer.MULTIPLY:              "Multiplication",
+	lexer.DIVIDE:                "Division",
+	lexer.EQUAL:                 "Equality",
+	lexer.NOT_EQUAL:             "Equality",
+	lexer.LESS_THAN:             "Ordering",
+	lexer.LESS_THAN_OR_EQUAL:    "Ordering",
+	lexer.GREATER_THAN:          "Ordering",
+	lexer.GREATER_THAN_OR_EQUAL: "Ordering",
+}
+
+// callOperator calls the method overloading an operator through the built-in trait, ok is false
+// when left is not of a comp or an enum implementing it
+func callOperator(trait string, left, right env.Value, source utils.String) (result env.Value, ok bool, err error) {
+	builtin := env.BuiltinTrait(trait)
+	impl, ok := env.FindImpl(left.Type(), builtin)
+	if !ok {
+		return nil, false, nil
+	}
+	method := impl.Methods[builtin.Methods[0].Name]
+	result, err = CallValue(method, []Argument{{Value: left, Source: source}, {Value: right, Source: source}}, source)
+	return result, true, err
+}
+
+// overloadedOperator applies op to comps and enums implementing its built-in trait, ok is false
+// when left does not. Ordering only provides less, `a > b` is `b.less(a)` and `a >= b` is
+// `!a.less(b)`.
+func overloadedOperator(op lexer.TokenType, left, right env.Value, source utils.String) (result env.Value, ok bool, err error) {
+	trait, ok := OperatorTraits[op]
+	if !ok || left.IsBase() {
+		return nil, false, nil
+	}
+	if op == lexer.GREATER_THAN || op == lexer.LESS_THAN_OR_EQUAL {
+		left, right = right, left
+	}
+	result, ok, err = callOperator(trait, left, right, source)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	if op == lexer.NOT_EQUAL || op == lexer.LESS_THAN_OR_EQUAL || op == lexer.GREATER_THAN_OR_EQUAL {
+		return env.NewBoolValue(!env.GetBaseTypeValues[bool](result)[0], source), true, nil
+	}
+	return result, true, nil
+}
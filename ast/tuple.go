@@ -0,0 +1,120 @@
+package ast
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// TupleExpr is a parenthesised, comma separated group of expressions `(a, b, c)`
+type TupleExpr struct {
+	source utils.String
+	Items  []Expr
+}
+
+func NewTupleExpr(items []Expr, source utils.String) TupleExpr {
+	return TupleExpr{source: source, Items: items}
+}
+
+func (t TupleExpr) Source() utils.String {
+	return t.source
+}
+
+func (t TupleExpr) Eval(e *env.Env) (env.Value, error) {
+	return t.evalItems(e, nil)
+}
+
+// evalItems builds the tuple, types are the expected element types or nil when unknown
+func (t TupleExpr) evalItems(e *env.Env, types []env.Type) (env.Value, error) {
+	items := make([]env.Value, len(t.Items))
+	for i, item := range t.Items {
+		var expected env.Type
+		if types != nil {
+			expected = types[i]
+		}
+		value, err := evalAs(e, item, expected)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, utils.Error{Source: item.Source(), Message: "expression has no value"}
+		}
+		items[i] = value
+	}
+	return env.NewTupleValue(items, t.source), nil
+}
+
+// Pattern is the left-hand side of a destructuring declaration,
+// either a single name (`_` discards the value) or a tuple of patterns
+type Pattern struct {
+	source utils.String
+	Name   string
+	Elems  []Pattern
+}
+
+func NewNamePattern(source utils.String) Pattern {
+	return Pattern{source: source, Name: source.String()}
+}
+
+func NewTuplePattern(elems []Pattern, source utils.String) Pattern {
+	return Pattern{source: source, Elems: elems}
+}
+
+func (p Pattern) Source() utils.String {
+	return p.source
+}
+
+func (p Pattern) IsTuple() bool {
+	return p.Elems != nil
+}
+
+// Bind declares the names of the pattern in e with the matching parts of value
+func (p Pattern) Bind(e *env.Env, value env.Value, isConst bool) error {
+	if !p.IsTuple() {
+		if p.Name != "_" {
+			e.Set(p.Name, value, isConst)
+		}
+		return nil
+	}
+	tuple, ok := value.(*env.TupleValue)
+	if !ok {
+		return utils.Error{Source: p.source, Message: fmt.Sprintf("cannot destructure %s into a tuple pattern", typeNameOf(value))}
+	}
+	if len(tuple.Items) != len(p.Elems) {
+		return utils.Error{Source: p.source, Message: fmt.Sprintf("cannot destructure %s into %d names", tuple.Type().Name(), len(p.Elems))}
+	}
+	for i, elem := range p.Elems {
+		if err := elem.Bind(e, tuple.Items[i], isConst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DestructureExpr declares every name of a pattern, `(r, g, b) := color`
+type DestructureExpr struct {
+	source  utils.String
+	Pattern Pattern
+	Const   bool
+	Value   Expr
+}
+
+func NewDestructureExpr(pattern Pattern, value Expr, isConst bool, source utils.String) DestructureExpr {
+	return DestructureExpr{source: source, Pattern: pattern, Const: isConst, Value: value}
+}
+
+func (d DestructureExpr) Source() utils.String {
+	return d.source
+}
+
+func (d DestructureExpr) Eval(e *env.Env) (env.Value, error) {
+	value, err := d.Value.Eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Pattern.Bind(e, env.Copy(value), d.Const); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -1,26 +1,45 @@
 package ast
 
 import (
+	"fmt"
+
 	"com.loop.anonx3247/env"
 	"com.loop.anonx3247/lexer"
 	"com.loop.anonx3247/utils"
 )
 
+// Numeric semantics of unary operators, see binary_expr.go for binary ones:
+//
+//	`-x`     numbers   `0 - x` for integers following the overflow policy, only -0 fits an unsigned type
+//	`+x`     numbers   x unchanged
+//	`~x`     integers  bitwise complement
+//	`not x`  bools
 type UnaryExpr struct {
 	source utils.String
 	Op     lexer.TokenType
 	Value  Expr
 }
 
+func NewUnaryExpr(op lexer.Token, value Expr) UnaryExpr {
+	return UnaryExpr{
+		source: utils.Encompass(op.Value, value.Source()),
+		Op:     op.Type,
+		Value:  value,
+	}
+}
+
 func (u UnaryExpr) Source() utils.String {
 	return u.source
 }
 
-func (u UnaryExpr) Eval(env *env.Env) (env.Value, error) {
-	val, err := u.Value.Eval(env)
+func (u UnaryExpr) Eval(e *env.Env) (env.Value, error) {
+	val, err := u.Value.Eval(e)
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, utils.Error{Source: u.Value.Source(), Message: "expression has no value"}
+	}
 	switch u.Op {
 	case lexer.ADDRESS_OF:
 		// TODO: implement address_of operation
@@ -30,10 +49,16 @@ func (u UnaryExpr) Eval(env *env.Env) (env.Value, error) {
 	case lexer.NOT:
 		return Not(val, u.Source())
 	case lexer.MINUS:
-		return Minus(val, u.Source())
+		return Minus(val, e.Overflow(), u.Source())
+	case lexer.PLUS:
+		return Plus(val, u.Source())
 	}
-	return nil, utils.Error{Source: u.Source(), Message: "unsupported unary operator BITWISE_NOT on non-integer type"}
+	return nil, unaryError(u.Op, val, u.Source())
+}
 
+// unaryError reports a unary operator applied to a value it does not support
+func unaryError(op lexer.TokenType, val env.Value, source utils.String) error {
+	return utils.Error{Source: source, Message: fmt.Sprintf("cannot apply %s to %s", OperatorSymbols[op], typeNameOf(val))}
 }
 
 func AddressOf(val env.Value, source utils.String) (env.Value, error) {
@@ -75,7 +100,7 @@ func BitwiseNot(val env.Value, source utils.String) (env.Value, error) {
 			return env.NewU8Value(^intVal.GetValue(), source), nil
 		}
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for bitwise not"}
+	return nil, unaryError(lexer.BITWISE_NOT, val, source)
 }
 
 func Not(val env.Value, source utils.String) (env.Value, error) {
@@ -84,27 +109,15 @@ func Not(val env.Value, source utils.String) (env.Value, error) {
 			return env.NewBoolValue(!boolVal.GetValue(), source), nil
 		}
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for not"}
+	return nil, unaryError(lexer.NOT, val, source)
 }
 
-func Minus(val env.Value, source utils.String) (env.Value, error) {
+// Minus negates a number, for integers `-x` is `0 - x` following the overflow policy:
+// -(-128i8) does not fit in i8 and neither does the negation of a non-zero unsigned integer
+func Minus(val env.Value, overflow env.OverflowPolicy, source utils.String) (env.Value, error) {
 	switch val.Type().BaseType() {
-	case env.I8:
-		if intVal, ok := val.(env.BaseValue[int8]); ok {
-			return env.NewI8Value(-intVal.GetValue(), source), nil
-		}
-	case env.I16:
-		if intVal, ok := val.(env.BaseValue[int16]); ok {
-			return env.NewI16Value(-intVal.GetValue(), source), nil
-		}
-	case env.I32:
-		if intVal, ok := val.(env.BaseValue[int32]); ok {
-			return env.NewI32Value(-intVal.GetValue(), source), nil
-		}
-	case env.I64:
-		if intVal, ok := val.(env.BaseValue[int64]); ok {
-			return env.NewI64Value(-intVal.GetValue(), source), nil
-		}
+	case env.I8, env.I16, env.I32, env.I64, env.U8, env.U16, env.U32, env.U64:
+		return integerArithmetic(lexer.MINUS, nil, val, overflow, source)
 	case env.F32:
 		if floatVal, ok := val.(env.BaseValue[float32]); ok {
 			return env.NewF32Value(-floatVal.GetValue(), source), nil
@@ -114,5 +127,13 @@ func Minus(val env.Value, source utils.String) (env.Value, error) {
 			return env.NewF64Value(-floatVal.GetValue(), source), nil
 		}
 	}
-	return nil, utils.Error{Source: source, Message: "unsupported types for minus"}
+	return nil, unaryError(lexer.MINUS, val, source)
+}
+
+// Plus returns numbers unchanged, `+x` only documents that x is positive
+func Plus(val env.Value, source utils.String) (env.Value, error) {
+	if val == nil || !val.IsBase() || val.Type().BaseType() > env.F64 {
+		return nil, unaryError(lexer.PLUS, val, source)
+	}
+	return val, nil
 }
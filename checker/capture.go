@@ -0,0 +1,204 @@
+package checker
+
+import (
+	"com.loop.anonx3247/ast"
+)
+
+// assignments walks expressions to find the variables they assign, a call can assign a variable
+// captured by the function it calls, so that variable cannot stay narrowed, see narrowings.
+// Variables are told apart by their names only, which may find more assignments than there are.
+type assignments struct {
+	scopes   []assignmentScope // scopes entered by the walk, innermost last
+	free     map[string]bool   // variables assigned that are declared outside of the walked expressions
+	captured map[string]bool   // variables assigned by a function they are declared outside of
+}
+
+type assignmentScope struct {
+	names    map[string]bool
+	function bool // the scope of the parameters of a function
+}
+
+func newAssignments() *assignments {
+	return &assignments{free: map[string]bool{}, captured: map[string]bool{}}
+}
+
+// assignedIn returns the variables declared outside of exprs that exprs assign
+func assignedIn(exprs ...ast.Expr) map[string]bool {
+	a := newAssignments()
+	a.exprs(exprs)
+	return a.free
+}
+
+func (a *assignments) enter(function bool) {
+	a.scopes = append(a.scopes, assignmentScope{names: map[string]bool{}, function: function})
+}
+
+func (a *assignments) leave() {
+	a.scopes = a.scopes[:len(a.scopes)-1]
+}
+
+func (a *assignments) declare(name string) {
+	if len(a.scopes) == 0 {
+		a.enter(false)
+	}
+	a.scopes[len(a.scopes)-1].names[name] = true
+}
+
+// assign records an assignment to name from the innermost scope
+func (a *assignments) assign(name string) {
+	inFunction := false
+	for i := len(a.scopes) - 1; i >= 0; i-- {
+		if a.scopes[i].names[name] {
+			if inFunction {
+				a.captured[name] = true
+			}
+			return
+		}
+		inFunction = inFunction || a.scopes[i].function
+	}
+	a.free[name] = true
+	if inFunction {
+		a.captured[name] = true
+	}
+}
+
+func (a *assignments) exprs(exprs []ast.Expr) {
+	for _, expr := range exprs {
+		a.expr(expr)
+	}
+}
+
+func (a *assignments) scope(s *ast.Scope) {
+	a.enter(false)
+	a.exprs(s.Exprs)
+	a.leave()
+}
+
+func (a *assignments) expr(expr ast.Expr) {
+	switch n := expr.(type) {
+	case ast.AssignmentExpr:
+		a.expr(n.Value)
+		if n.Kind == ast.DECLARATION {
+			a.declare(n.Name)
+		} else {
+			a.assign(n.Name)
+		}
+	case ast.DestructureExpr:
+		a.expr(n.Value)
+		a.pattern(n.Pattern)
+	case ast.PlaceAssignmentExpr:
+		a.expr(n.Target)
+		a.expr(n.Value)
+	case *ast.Scope:
+		a.scope(n)
+	case ast.ParenExpr:
+		a.expr(n.Expr)
+	case ast.InterpolatedString:
+		a.exprs(n.Parts)
+	case *ast.BinaryExpr:
+		a.expr(*n.Left)
+		a.expr(*n.Right)
+	case ast.BinaryExpr:
+		a.expr(*n.Left)
+		a.expr(*n.Right)
+	case ast.UnaryExpr:
+		a.expr(n.Value)
+	case ast.ConversionExpr:
+		a.expr(n.Value)
+	case ast.IsNoneExpr:
+		a.expr(n.Value)
+	case ast.ConditionalExpr:
+		for branch := &n; branch != nil; branch = branch.Next {
+			a.expr(branch.Condition)
+			a.scope(&branch.Content)
+		}
+	case ast.FunctionDecl:
+		if n.Name != "" {
+			a.declare(n.Name)
+		}
+		a.function(n)
+	case ast.CallExpr:
+		a.expr(n.Callee)
+		for _, arg := range n.Args {
+			a.expr(arg.Value)
+		}
+	case ast.ReturnExpr:
+		if n.Value != nil {
+			a.expr(n.Value)
+		}
+	case ast.BreakExpr:
+		if n.Value != nil {
+			a.expr(n.Value)
+		}
+	case ast.WhileExpr:
+		a.expr(n.Condition)
+		a.scope(&n.Body)
+	case ast.LoopExpr:
+		a.scope(&n.Body)
+	case ast.ForExpr:
+		a.expr(n.Iterable)
+		a.enter(false)
+		a.pattern(n.Pattern)
+		a.scope(&n.Body)
+		a.leave()
+	case ast.ListExpr:
+		a.exprs(n.Items)
+	case ast.TupleExpr:
+		a.exprs(n.Items)
+	case ast.IndexExpr:
+		a.expr(n.Target)
+		a.expr(n.Index)
+	case ast.FieldExpr:
+		a.expr(n.Target)
+	case ast.MatchExpr:
+		a.expr(n.Scrutinee)
+		for _, arm := range n.Arms {
+			a.enter(false)
+			a.matchPattern(arm.Pattern)
+			a.expr(arm.Body)
+			a.leave()
+		}
+	case ast.ImplDecl:
+		for _, method := range n.Methods {
+			a.function(method)
+		}
+		for _, constant := range n.Constants {
+			a.expr(constant.Value)
+		}
+	case ast.PubDecl:
+		a.expr(n.Decl)
+	}
+}
+
+func (a *assignments) function(f ast.FunctionDecl) {
+	a.enter(true)
+	defer a.leave()
+	if f.Name != "" {
+		a.declare(f.Name)
+	}
+	for _, param := range f.Params {
+		if expr, ok := param.Default.(ast.Expr); ok {
+			a.expr(expr)
+		}
+		a.declare(param.Name)
+	}
+	a.expr(f.Body)
+}
+
+func (a *assignments) pattern(p ast.Pattern) {
+	if !p.IsTuple() {
+		a.declare(p.Name)
+	}
+	for _, elem := range p.Elems {
+		a.pattern(elem)
+	}
+}
+
+func (a *assignments) matchPattern(p ast.MatchPattern) {
+	if p.Kind == ast.BindingPattern {
+		a.declare(p.Name)
+	}
+	for _, elem := range p.Elems {
+		a.matchPattern(elem)
+	}
+}
@@ -0,0 +1,305 @@
+package checker
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// Checker infers and verifies the types of a program before it is evaluated.
+// Types that cannot be known statically are nil, and nil is compatible with everything,
+// so the checker only reports errors the interpreter would run into. Expressions known to
+// have no value are of type env.Nothing, which is compatible with no other type.
+type Checker struct {
+	scope  *scope
+	errors utils.Errors
+	fn     *function // function whose body is being checked, nil at the top level
+	loops  []*loop   // loops enclosing the current expression within fn
+
+	captured map[string]bool              // variables assigned by functions, see assignments
+	modules  map[moduleKey]env.ModuleType // imported modules, see DeclareModule
+}
+
+type variable struct {
+	Type     env.Type
+	Const    bool
+	optional env.Type // declared T? of a variable narrowed to T, see narrowing
+	public   bool     // declared with pub at the top level of a module
+}
+
+type scope struct {
+	parent *scope
+	vars   map[string]variable
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, vars: map[string]variable{}}
+}
+
+func (s *scope) declare(name string, t env.Type, isConst bool) {
+	s.vars[name] = variable{Type: t, Const: isConst}
+}
+
+// update replaces the variable name in the innermost scope declaring it
+func (s *scope) update(name string, v variable) {
+	for current := s; current != nil; current = current.parent {
+		if _, ok := current.vars[name]; ok {
+			current.vars[name] = v
+			return
+		}
+	}
+}
+
+func (s *scope) lookup(name string) (variable, bool) {
+	for current := s; current != nil; current = current.parent {
+		if v, ok := current.vars[name]; ok {
+			return v, true
+		}
+	}
+	return variable{}, false
+}
+
+type function struct {
+	name    string
+	ret     env.Type // declared return type, nil when inferred
+	returns results  // values given to ret
+}
+
+type loop struct {
+	allowValue bool    // only `loop` can be left with `break value`
+	breaks     results // values given to break, nothing for a bare break
+}
+
+// results are the types of the values an expression may finish with and where each comes from
+type results struct {
+	types   []env.Type
+	sources []utils.String
+}
+
+func (r *results) add(t env.Type, source utils.String) {
+	r.types = append(r.types, t)
+	r.sources = append(r.sources, source)
+}
+
+// typeValue is the type of an expression naming a comp or an enum, `Person` in `Person(name: 'Anas')`
+type typeValue struct {
+	env.Type
+}
+
+func (t typeValue) Name() string {
+	return "type " + t.Type.Name()
+}
+
+// typeParam is the type of an expression naming a type parameter, `T` in `T.zero`
+type typeParam struct {
+	generic
+}
+
+func (t typeParam) Name() string {
+	return "type " + t.param.Name
+}
+
+// generic is the type of the values of a type parameter within its generic function, `x` in
+// `fn double<T implements Addition>(x: T) -> x + x`. They only have the operators, methods and
+// constants of the traits bounding T, which the type argument of every call implements.
+type generic struct {
+	param  env.TypeParam
+	traits []*env.TraitType // the declared traits among the bounds of the parameter
+}
+
+func (g generic) BaseType() env.BaseType {
+	return env.Named
+}
+
+func (g generic) Name() string {
+	return g.param.Name
+}
+
+// implements reports whether the bounds of the parameter include trait
+func (g generic) implements(trait *env.TraitType) bool {
+	for _, bound := range g.traits {
+		if bound == trait {
+			return true
+		}
+	}
+	return false
+}
+
+// builtin is the type of a function provided by the interpreter, see env/builtins.go
+type builtin struct {
+	name string
+}
+
+func (b builtin) BaseType() env.BaseType {
+	return env.Function
+}
+
+func (b builtin) Name() string {
+	return "fn"
+}
+
+// New creates a checker whose top level knows the builtins, a checker keeps
+// the declarations of every program it checks, which is what the REPL needs
+func New() *Checker {
+	root := newScope(nil)
+	for _, name := range env.BuiltinNames() {
+		root.declare(name, builtin{name: name}, true)
+	}
+	for _, trait := range env.BuiltinTraits {
+		root.declare(trait.Name(), typeValue{trait}, true)
+	}
+	return &Checker{scope: root, captured: map[string]bool{}}
+}
+
+// Check verifies a whole program and reports every error found as utils.Errors
+func Check(program *ast.Scope) error {
+	return New().Check(program)
+}
+
+// Check verifies program in the top level scope of the checker
+func (c *Checker) Check(program *ast.Scope) error {
+	c.errors = nil
+	for i, expr := range program.Exprs {
+		if _, ok := expr.(ast.ModDecl); ok && i > 0 {
+			c.errorf(expr.Source(), "mod must come first in a file")
+		}
+	}
+	// functions may be called before their declaration, so those of the whole program are walked first
+	a := newAssignments()
+	a.exprs(program.Exprs)
+	for name := range a.captured {
+		c.captured[name] = true
+	}
+	c.statements(program.Exprs)
+	if len(c.errors) > 0 {
+		return c.errors
+	}
+	return nil
+}
+
+func (c *Checker) errorf(source utils.String, format string, args ...any) {
+	c.errors = append(c.errors, utils.Error{Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+// resolve replaces a reference to a user type by its declaration, `Person?` is resolved to an
+// optional of the declaration
+func (c *Checker) resolve(t env.Type) env.Type {
+	if optional, ok := t.(env.OptionalType); ok && optional.Elem != nil {
+		return env.OptionalType{Elem: c.resolve(optional.Elem)}
+	}
+	named, ok := t.(env.NamedType)
+	if !ok {
+		return t
+	}
+	if named.Module != "" {
+		return c.qualified(named)
+	}
+	if v, ok := c.scope.lookup(named.TypeName); ok {
+		switch declared := v.Type.(type) {
+		case typeValue:
+			return declared.Type
+		case typeParam:
+			return declared.generic
+		}
+	}
+	return t
+}
+
+// implements reports whether values of type t have the operations of trait, within a generic
+// function those of a type parameter are the ones of its bounds
+func implements(t env.Type, trait *env.TraitType) bool {
+	if g, ok := t.(generic); ok {
+		return g.implements(trait)
+	}
+	return trait.ImplementedBy(t)
+}
+
+// trait finds the trait named by a bound
+func (c *Checker) trait(name string) (*env.TraitType, bool) {
+	if v, ok := c.scope.lookup(name); ok {
+		if declared, ok := v.Type.(typeValue); ok {
+			trait, ok := declared.Type.(*env.TraitType)
+			return trait, ok
+		}
+	}
+	return nil, false
+}
+
+// annotation reports the user types of an annotation that are not declared
+func (c *Checker) annotation(t env.Type, source utils.String) {
+	switch t := t.(type) {
+	case env.NamedType:
+		switch c.resolve(t).(type) {
+		case env.NamedType:
+			if t.Module != "" {
+				c.qualifiedError(t, source)
+			} else {
+				c.errorf(source, "unknown type %s", t.TypeName)
+			}
+		case *env.TraitType:
+			c.errorf(source, "%s is a trait, not a type", t.TypeName)
+		}
+	case env.ListType:
+		c.annotation(t.Elem, source)
+	case env.OptionalType:
+		c.annotation(t.Elem, source)
+	case env.TupleType:
+		for _, elem := range t.Elems {
+			c.annotation(elem, source)
+		}
+	case env.FunctionType:
+		for _, param := range t.Params {
+			c.annotation(param, source)
+		}
+		c.annotation(t.Return, source)
+	}
+}
+
+// common returns the type shared by types, T? when some are T and others none or T?. The nil types
+// of expressions that never finish, as `ret`, are left out. When none of them has a value the result
+// is nothing, when only some have one it is unknown. Types that differ are reported at the source of the first one
+// differing from those before it, as in "branches have different types i32 and str".
+func (c *Checker) common(types []env.Type, sources []utils.String, what string) env.Type {
+	var shared env.Type
+	optional, nothing := false, false
+	for i, t := range types {
+		if t == nil {
+			continue
+		}
+		if t == env.Nothing {
+			nothing = true
+			continue
+		}
+		if o, ok := t.(env.OptionalType); ok {
+			optional = true
+			if o.Elem == nil {
+				continue
+			}
+			t = o.Elem
+		}
+		if shared == nil {
+			shared = t
+		} else if !env.SameType(t, shared) {
+			c.errorf(sources[i], "%s have different types %s and %s", what, shared.Name(), t.Name())
+			return nil
+		}
+	}
+	switch {
+	case nothing && shared == nil && !optional:
+		return env.Nothing
+	case nothing:
+		return nil
+	case optional:
+		return env.OptionalType{Elem: shared}
+	}
+	return shared
+}
+
+func typeName(t env.Type) string {
+	if t == nil {
+		return "_"
+	}
+	return t.Name()
+}
@@ -0,0 +1,582 @@
+package checker
+
+import (
+	"strconv"
+
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// statements checks a sequence of expressions whose values are not used in the current scope.
+// Functions, comps, enums, traits and impls are declared first so they can be used before their
+// declaration.
+func (c *Checker) statements(exprs []ast.Expr) {
+	c.hoistAll(exprs)
+	for _, expr := range exprs {
+		c.statement(expr)
+		c.narrowAfter(expr)
+	}
+}
+
+// statement checks an expression whose value is not used, the branches of a conditional or the arms
+// of a match used as a statement may then have different types. The values given to break and ret
+// are always used.
+func (c *Checker) statement(expr ast.Expr) {
+	switch n := expr.(type) {
+	case ast.ConditionalExpr:
+		c.conditional(n, nil, false)
+	case ast.MatchExpr:
+		c.match(n, false)
+	case *ast.Scope:
+		c.scopedStatements(n)
+	case ast.ParenExpr:
+		c.statement(n.Expr)
+	default:
+		c.expr(expr)
+	}
+}
+
+// value checks an expression whose value is used where a value of type t is expected, see exprAs,
+// the expression must have a value
+func (c *Checker) value(expr ast.Expr, t env.Type) env.Type {
+	v := c.exprAs(expr, t)
+	if v == env.Nothing {
+		c.errorf(expr.Source(), "expression has no value")
+		return nil
+	}
+	return v
+}
+
+// hoistAll declares the types of exprs, then records their impls which refer to those types
+func (c *Checker) hoistAll(exprs []ast.Expr) {
+	for _, expr := range exprs {
+		c.hoist(expr)
+	}
+	for _, expr := range exprs {
+		if n, ok := expr.(ast.ImplDecl); ok {
+			c.hoistImpl(n)
+		}
+	}
+}
+
+func (c *Checker) hoist(expr ast.Expr) {
+	switch n := expr.(type) {
+	case ast.FunctionDecl:
+		if n.Name != "" {
+			c.scope.declare(n.Name, functionType(n), true)
+		}
+	case ast.CompDecl:
+		c.scope.declare(n.Name, typeValue{env.NewStructType(n.Name, n.Fields, n.Source())}, true)
+	case ast.EnumDecl:
+		c.scope.declare(n.Name, typeValue{env.NewEnumType(n.Name, n.Variants, n.Source())}, true)
+	case ast.AbsDecl:
+		c.scope.declare(n.Name, typeValue{env.NewTraitType(n.Name, n.Methods, n.Constants, n.Source())}, true)
+	case ast.PubDecl:
+		c.hoist(n.Decl)
+	case ast.ImportExpr:
+		// impls and functions may name the types of a module imported after them
+		c.declareImport(n)
+	}
+}
+
+// block checks a scope in a new child of the current scope
+func (c *Checker) block(s *ast.Scope) env.Type {
+	return c.blockAs(s, nil)
+}
+
+// blockAs checks a scope whose last expression is expected to have type t
+func (c *Checker) blockAs(s *ast.Scope, t env.Type) env.Type {
+	return c.scoped(s, func(last ast.Expr) env.Type { return c.exprAs(last, t) })
+}
+
+// scopedStatements checks a scope whose value is not used
+func (c *Checker) scopedStatements(s *ast.Scope) {
+	c.scoped(s, func(last ast.Expr) env.Type {
+		c.statement(last)
+		return nil
+	})
+}
+
+// scoped checks a scope in a new child of the current scope, its last expression with check.
+// An empty scope has no value.
+func (c *Checker) scoped(s *ast.Scope, check func(last ast.Expr) env.Type) env.Type {
+	outer := c.scope
+	c.scope = newScope(outer)
+	defer func() { c.scope = outer }()
+	c.hoistAll(s.Exprs)
+	var last env.Type = env.Nothing
+	for i, expr := range s.Exprs {
+		if i == len(s.Exprs)-1 {
+			last = check(expr)
+		} else {
+			c.statement(expr)
+		}
+		c.narrowAfter(expr)
+	}
+	return last
+}
+
+func (c *Checker) expr(expr ast.Expr) env.Type {
+	switch n := expr.(type) {
+	case ast.Literal:
+		return env.TypeOf(n.Value)
+	case ast.Identifier:
+		v, ok := c.scope.lookup(n.Name())
+		if !ok {
+			c.errorf(n.Source(), "variable not found")
+			return nil
+		}
+		return v.Type
+	case ast.InterpolatedString:
+		for _, part := range n.Parts {
+			c.value(part, nil)
+		}
+		return env.Str
+	case ast.ParenExpr:
+		return c.expr(n.Expr)
+	case *ast.Scope:
+		return c.block(n)
+	case ast.AssignmentExpr:
+		return c.assignment(n)
+	case ast.PlaceAssignmentExpr:
+		return c.placeAssignment(n)
+	case *ast.BinaryExpr:
+		return c.binaryExpr(*n, nil)
+	case ast.BinaryExpr:
+		return c.binaryExpr(n, nil)
+	case ast.UnaryExpr:
+		return c.unary(n.Op, c.resolve(c.value(n.Value, nil)), n.Source())
+	case ast.ConditionalExpr:
+		return c.conditional(n, nil, true)
+	case ast.FunctionDecl:
+		return c.functionDecl(n)
+	case ast.CallExpr:
+		return c.call(n)
+	case ast.ReturnExpr:
+		return c.ret(n)
+	case ast.WhileExpr:
+		// the condition is checked again after each run of the body
+		c.widen(assignedIn(n.Condition, &n.Body))
+		c.condition(n.Condition)
+		c.loopBody(&n.Body, false)
+		return env.Nothing
+	case ast.LoopExpr:
+		return c.loopBody(&n.Body, true)
+	case ast.ForExpr:
+		c.forLoop(n)
+		return env.Nothing
+	case ast.BreakExpr:
+		return c.breakExpr(n)
+	case ast.ContinueExpr:
+		if len(c.loops) == 0 {
+			c.errorf(n.Source(), "continue outside of a loop")
+		}
+		return nil
+	case ast.ListExpr:
+		return c.list(n, nil)
+	case ast.IndexExpr:
+		return c.index(n)
+	case ast.TupleExpr:
+		return c.tuple(n, nil)
+	case ast.FieldExpr:
+		return c.field(c.resolve(c.expr(n.Target)), n.Field, n)
+	case ast.DestructureExpr:
+		value := c.value(n.Value, nil)
+		c.bindPattern(n.Pattern, value, n.Const)
+		return value
+	case ast.CompDecl:
+		for _, field := range n.Fields {
+			c.annotation(field.Type, n.Source())
+		}
+		v, _ := c.scope.lookup(n.Name)
+		return v.Type
+	case ast.EnumDecl:
+		for _, variant := range n.Variants {
+			for _, t := range variant.Payload {
+				c.annotation(t, n.Source())
+			}
+		}
+		v, _ := c.scope.lookup(n.Name)
+		return v.Type
+	case ast.MatchExpr:
+		return c.match(n, true)
+	case ast.ConversionExpr:
+		return c.conversion(n)
+	case ast.IsNoneExpr:
+		return c.isNone(n)
+	case ast.AbsDecl:
+		return c.abs(n)
+	case ast.ImplDecl:
+		return c.impl(n)
+	case ast.ModDecl:
+		c.topLevel(n.Source(), "mod")
+		return nil
+	case ast.ImportExpr:
+		return c.importExpr(n)
+	case ast.PubDecl:
+		return c.pub(n)
+	}
+	return nil
+}
+
+func (c *Checker) assignment(a ast.AssignmentExpr) env.Type {
+	if a.Kind == ast.DECLARATION {
+		value := c.value(a.Value, a.Type)
+		t := value
+		if optional, ok := value.(env.OptionalType); ok && optional.Elem == nil && a.Type == nil {
+			c.errorf(a.Value.Source(), "cannot infer the type of %s from none, annotate it as in %s : i32? = none", a.Name, a.Name)
+		}
+		if a.Type != nil {
+			c.annotation(a.Type, a.Source())
+			if !env.SameType(value, a.Type) {
+				c.errorf(a.Value.Source(), "expected %s for %s, got %s", a.Type.Name(), a.Name, value.Name())
+			}
+			t = a.Type
+		}
+		c.scope.declare(a.Name, t, a.Const)
+		return t
+	}
+
+	v, ok := c.scope.lookup(a.Name)
+	if !ok {
+		c.expr(a.Value)
+		c.errorf(a.Source(), "variable not found")
+		return nil
+	}
+	target := v.Type
+	if _, compound := ast.CompoundOperators[a.Kind]; v.optional != nil && !compound {
+		// a narrowed optional can be set back to none
+		target = v.optional
+	}
+	value := c.value(a.Value, c.assignedType(a.Kind, target))
+	if v.Const {
+		c.errorf(a.Source(), "cannot assign to immutable variable %s, declare it with mut", a.Name)
+	}
+	c.assign(a.Kind, target, value, a.Name, a.Value)
+	if v.optional != nil && a.Kind == ast.ASSIGNMENT && !env.SameType(value, v.Type) {
+		c.scope.update(a.Name, widened(v))
+	}
+	return target
+}
+
+func (c *Checker) placeAssignment(a ast.PlaceAssignmentExpr) env.Type {
+	c.mutableRoot(a.Target, "assign through")
+	target := c.expr(a.Target)
+	value := c.value(a.Value, c.assignedType(a.Kind, target))
+	c.assign(a.Kind, target, value, a.Target.Source().String(), a.Value)
+	return target
+}
+
+// mutableRoot reports a place expression starting from an immutable variable, see
+// ast.checkMutableRoot
+func (c *Checker) mutableRoot(place ast.Expr, action string) {
+	for {
+		switch p := place.(type) {
+		case ast.FieldExpr:
+			place = p.Target
+		case ast.IndexExpr:
+			place = p.Target
+		case ast.ParenExpr:
+			place = p.Expr
+		case ast.Identifier:
+			if v, ok := c.scope.lookup(p.Name()); ok && v.Const {
+				c.errorf(p.Source(), "cannot %s immutable variable %s, declare it with mut", action, p.Name())
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// exprAs checks expr where a value of type t is expected, mirroring ast.evalAs:
+// untyped number literals are given type t, or T where a T? is expected
+func (c *Checker) exprAs(expr ast.Expr, t env.Type) env.Type {
+	t = env.Unwrap(t)
+	if _, ok, err := ast.CoerceLiteral(expr, c.resolve(t)); ok {
+		if err != nil {
+			c.errors = append(c.errors, err.(utils.Error))
+		}
+		return t
+	}
+	switch n := expr.(type) {
+	case ast.ParenExpr:
+		return c.exprAs(n.Expr, t)
+	case ast.ListExpr:
+		if list, ok := c.resolve(t).(env.ListType); ok {
+			return c.list(n, list.Elem)
+		}
+	case ast.TupleExpr:
+		if tuple, ok := c.resolve(t).(env.TupleType); ok && len(tuple.Elems) == len(n.Items) {
+			return c.tuple(n, tuple.Elems)
+		}
+	case *ast.Scope:
+		return c.blockAs(n, t)
+	case ast.ConditionalExpr:
+		return c.conditional(n, t, true)
+	case *ast.BinaryExpr:
+		return c.binaryExpr(*n, t)
+	case ast.BinaryExpr:
+		return c.binaryExpr(n, t)
+	}
+	return c.expr(expr)
+}
+
+// assignedType is the type a literal stored in a place of type t takes, see ast.assignedType
+func (c *Checker) assignedType(kind ast.AssignmentKind, t env.Type) env.Type {
+	if op, ok := ast.CompoundOperators[kind]; ok {
+		return c.operandType(op, t)
+	}
+	return t
+}
+
+// assign checks that value can be stored in a place of type target with the given kind of assignment
+func (c *Checker) assign(kind ast.AssignmentKind, target, value env.Type, name string, valueExpr ast.Expr) {
+	if op, ok := ast.CompoundOperators[kind]; ok {
+		c.divisor(op, valueExpr, valueExpr.Source())
+		c.binary(op, target, value, valueExpr.Source())
+		return
+	}
+	if _, ok := c.resolve(target).(env.OptionalType); kind == ast.OPTIONAL_ASSIGNMENT && target != nil && !ok {
+		c.errorf(valueExpr.Source(), "cannot use ?= on %s of type %s, only optionals can be none", name, target.Name())
+		return
+	}
+	if !env.SameType(value, target) {
+		c.errorf(valueExpr.Source(), "cannot assign %s to %s of type %s", value.Name(), name, target.Name())
+	}
+}
+
+// conversion checks `x as T`, conversions of literals are evaluated so that overflows are reported early
+func (c *Checker) conversion(n ast.ConversionExpr) env.Type {
+	value := c.resolve(c.value(n.Value, nil))
+	if value != nil && !env.CanConvert(value, n.Type) {
+		c.errorf(n.Source(), "cannot convert %s to %s", value.Name(), n.Type.Name())
+	} else if literal, ok := n.Value.(ast.Literal); ok {
+		if _, err := env.Convert(literal.Value, n.Type, n.Mode, n.Source()); err != nil {
+			c.errors = append(c.errors, err.(utils.Error))
+		}
+	}
+	return n.Type
+}
+
+func (c *Checker) condition(condition ast.Expr) {
+	t := c.expr(condition)
+	if t != nil && !isBool(t) {
+		c.errorf(condition.Source(), "condition must be bool, got %s", t.Name())
+	}
+}
+
+// conditional checks an if expression where a value of type t is expected, when its value is not
+// used its branches may have different types
+func (c *Checker) conditional(n ast.ConditionalExpr, t env.Type, used bool) env.Type {
+	c.condition(n.Condition)
+	then, otherwise := c.narrowings(n.Condition)
+	content := c.narrowed(then, func() env.Type {
+		if used {
+			return c.blockAs(&n.Content, t)
+		}
+		c.scopedStatements(&n.Content)
+		return nil
+	})
+	if n.Next == nil {
+		if isElse(n) {
+			return content
+		}
+		// without an else branch the expression may have no value
+		return env.Nothing
+	}
+	next := c.narrowed(otherwise, func() env.Type { return c.conditional(*n.Next, t, used) })
+	if !used {
+		return nil
+	}
+	nextSource := n.Next.Source()
+	if isElse(*n.Next) {
+		nextSource = n.Next.Content.Source()
+	}
+	return c.common([]env.Type{content, next}, []utils.String{n.Content.Source(), nextSource}, "branches")
+}
+
+// isElse reports whether n is the else branch of a conditional, see ast.NewElseExpr
+func isElse(n ast.ConditionalExpr) bool {
+	literal, ok := n.Condition.(ast.Literal)
+	if !ok {
+		return false
+	}
+	b, ok := literal.Value.(env.BaseValue[bool])
+	return ok && b.GetValue()
+}
+
+// loopBody checks the body of a loop and returns the type of the values it breaks with
+func (c *Checker) loopBody(body *ast.Scope, allowValue bool) env.Type {
+	// a run of the body sees the variables the previous runs assigned
+	c.widen(assignedIn(body))
+	l := &loop{allowValue: allowValue}
+	c.loops = append(c.loops, l)
+	c.scopedStatements(body)
+	c.loops = c.loops[:len(c.loops)-1]
+	return c.common(l.breaks.types, l.breaks.sources, "break values")
+}
+
+func (c *Checker) forLoop(n ast.ForExpr) {
+	var item env.Type
+	switch t := c.resolve(c.expr(n.Iterable)).(type) {
+	case env.ListType:
+		item = t.Elem
+	case env.RangeType:
+		item = t.Elem
+	case nil:
+	default:
+		if t.BaseType() == env.Str {
+			item = env.Char
+			break
+		}
+		c.errorf(n.Iterable.Source(), "cannot iterate over a value of type %s", t.Name())
+	}
+	outer := c.scope
+	c.scope = newScope(outer)
+	c.bindPattern(n.Pattern, item, true)
+	c.loopBody(&n.Body, false)
+	c.scope = outer
+}
+
+func (c *Checker) breakExpr(n ast.BreakExpr) env.Type {
+	var value env.Type = env.Nothing
+	if n.Value != nil {
+		value = c.expr(n.Value)
+	}
+	if len(c.loops) == 0 {
+		c.errorf(n.Source(), "break outside of a loop")
+		return nil
+	}
+	l := c.loops[len(c.loops)-1]
+	if n.Value != nil && !l.allowValue {
+		c.errorf(n.Source(), "break with a value is only allowed in loop")
+	}
+	l.breaks.add(value, n.Source())
+	return nil
+}
+
+// list checks a list literal, elem is the expected element type or nil to use the type of the first element
+func (c *Checker) list(n ast.ListExpr, elem env.Type) env.Type {
+	for _, item := range n.Items {
+		t := c.value(item, elem)
+		if elem == nil {
+			elem = t
+		} else if !env.SameType(t, elem) {
+			c.errorf(item.Source(), "list element has type %s, expected %s", t.Name(), elem.Name())
+		}
+	}
+	return env.ListType{Elem: elem}
+}
+
+// tuple checks a tuple literal, types are the expected element types or nil when unknown
+func (c *Checker) tuple(n ast.TupleExpr, types []env.Type) env.Type {
+	elems := make([]env.Type, len(n.Items))
+	for i, item := range n.Items {
+		var expected env.Type
+		if types != nil {
+			expected = types[i]
+		}
+		elems[i] = c.value(item, expected)
+	}
+	return env.TupleType{Elems: elems}
+}
+
+func (c *Checker) index(n ast.IndexExpr) env.Type {
+	target := c.resolve(c.expr(n.Target))
+	index := c.expr(n.Index)
+	_, slice := index.(env.RangeType)
+	if index != nil && !slice && !isInteger(index) {
+		c.errorf(n.Index.Source(), "index must be an integer, got %s", index.Name())
+	}
+	switch t := target.(type) {
+	case env.ListType:
+		if slice {
+			return t
+		}
+		return t.Elem
+	case env.RangeType:
+		if slice {
+			return t
+		}
+		return t.Elem
+	case nil:
+		return nil
+	}
+	if target.BaseType() == env.Str {
+		if slice {
+			return env.Str
+		}
+		return env.Char
+	}
+	c.errorf(n.Source(), "cannot index a value of type %s", target.Name())
+	return nil
+}
+
+// field returns the type of `target.name`
+func (c *Checker) field(target env.Type, name string, n ast.FieldExpr) env.Type {
+	switch t := target.(type) {
+	case nil:
+		return nil
+	case env.TupleType:
+		i, err := strconv.Atoi(name)
+		if err == nil && i >= 0 && i < len(t.Elems) {
+			return t.Elems[i]
+		}
+		c.errorf(n.Source(), "tuple %s has no field %s", t.Name(), name)
+		return nil
+	case *env.StructType:
+		if i := t.FieldIndex(name); i >= 0 {
+			return t.Fields[i].Type
+		}
+	case env.ModuleType:
+		return c.member(t, name, n.Source())
+	case typeParam:
+		for _, bound := range t.param.Bounds {
+			if trait, ok := c.trait(bound); ok && trait.HasConstant(name) {
+				return env.NamedType{TypeName: t.param.Name}
+			}
+		}
+		c.errorf(n.Source(), "%s has no constant %s", t.param.Name, name)
+		return nil
+	case typeValue:
+		if enum, ok := t.Type.(*env.EnumType); ok {
+			i := enum.VariantIndex(name)
+			if i < 0 {
+				c.errorf(n.Source(), "%s has no variant %s", enum.Name(), name)
+				return nil
+			}
+			if enum.Variants[i].IsUnit() {
+				return enum
+			}
+			return env.FunctionType{Params: enum.Variants[i].Payload, Return: enum}
+		}
+	}
+	c.errorf(n.Source(), "%s has no field %s", target.Name(), name)
+	return nil
+}
+
+// bindPattern declares the names of a destructuring pattern with the matching parts of t
+func (c *Checker) bindPattern(p ast.Pattern, t env.Type, isConst bool) {
+	if !p.IsTuple() {
+		if p.Name != "_" {
+			c.scope.declare(p.Name, t, isConst)
+		}
+		return
+	}
+	elems := make([]env.Type, len(p.Elems))
+	if t != nil {
+		tuple, ok := c.resolve(t).(env.TupleType)
+		if !ok {
+			c.errorf(p.Source(), "cannot destructure %s into a tuple pattern", t.Name())
+		} else if len(tuple.Elems) != len(p.Elems) {
+			c.errorf(p.Source(), "cannot destructure %s into %d names", t.Name(), len(p.Elems))
+		} else {
+			elems = tuple.Elems
+		}
+	}
+	for i, elem := range p.Elems {
+		c.bindPattern(elem, elems[i], isConst)
+	}
+}
@@ -0,0 +1,42 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string // the error reported, empty when the program is valid
+	}{
+		// expressions without a value
+		{"fn f() { print(1) }\nf() + 1", "expression has no value"},
+		{"c := true\nx := if c { 1 }", "expression has no value"},
+		{"x := while false { }", "expression has no value"},
+		{"fn f(n: i32): i32 { }", "<fn f> should return i32, got nothing"},
+		{"fn f(n: i32): i32 {\nif n > 0 { ret n }\n}", "<fn f> should return i32, got nothing"},
+		{"fn f(n: i32): i32 {\nif n > 0 { ret }\nn\n}", "<fn f> should return i32, got nothing"},
+		{"fn f(n: i32): i32 {\nif n > 0 { ret 1 }\nret 2\n}", ""},
+		{"fn f(): i32 {\nloop { ret 1 }\n}", ""},
+		// values of different types
+		{"c := true\nx := if c { 1 } else { \"a\" }", "branches have different types i32 and str"},
+		{"c := true\nx := if c { 1 } elif c { 2 } else { \"a\" }", "branches have different types i32 and str"},
+		{"x := match 1 {\n1 => 1\n_ => \"a\"\n}", "match arms have different types i32 and str"},
+		{"x := loop {\nif true { break 1 }\nbreak \"a\"\n}", "break values have different types i32 and str"},
+		{"fn f(c: bool) {\nif c { ret 1 }\nret \"a\"\n}", "returned values have different types i32 and str"},
+		{"c := true\nx : i32? = if c { 1 } else { none }", ""},
+		// values that are not used
+		{"c := true\nif c { 1 } else { \"a\" }", ""},
+		{"match 1 {\n1 => 1\n_ => \"a\"\n}", ""},
+		{"fn f(c: bool) {\nif c { print(1) } else { 2 }\n}", ""},
+	}
+	for _, test := range tests {
+		err := check(t, test.source)
+		if test.err == "" && err != nil {
+			t.Errorf("checking %q failed: %v", test.source, err)
+		} else if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("checking %q gives %v, want %q", test.source, err, test.err)
+		}
+	}
+}
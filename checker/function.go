@@ -0,0 +1,412 @@
+package checker
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+func functionType(f ast.FunctionDecl) env.FunctionType {
+	return env.NewFunctionType(f.TypeParams, f.Params, f.Return)
+}
+
+func (c *Checker) functionDecl(f ast.FunctionDecl) env.Type {
+	fnType := functionType(f)
+	outerScope, outerFn, outerLoops := c.scope, c.fn, c.loops
+	c.scope = newScope(outerScope)
+	c.widenMutable()
+	c.fn = &function{name: f.Name, ret: f.Return}
+	c.loops = nil
+	for _, param := range f.TypeParams {
+		g := generic{param: param}
+		for _, bound := range param.Bounds {
+			if trait, ok := c.trait(bound); ok {
+				g.traits = append(g.traits, trait)
+			} else {
+				c.errorf(f.Source(), "unknown trait %s", bound)
+			}
+		}
+		c.scope.declare(param.Name, typeParam{g}, true)
+	}
+	for _, param := range f.Params {
+		c.annotation(param.Type, f.Source())
+	}
+	c.annotation(f.Return, f.Source())
+
+	if f.Name != "" {
+		// recursive calls see the function, its return type is unknown until inferred
+		c.scope.declare(f.Name, fnType, true)
+	}
+	for _, param := range f.Params {
+		// defaults are evaluated in the call, after the parameters before them are bound
+		if expr, ok := param.Default.(ast.Expr); ok {
+			if value := c.value(expr, param.Type); !env.SameType(value, param.Type) {
+				c.errorf(expr.Source(), "expected %s for %s, got %s", typeName(param.Type), param.Name, typeName(value))
+			}
+		}
+		c.scope.declare(param.Name, param.Type, !param.Mutable)
+	}
+	body := c.exprAs(f.Body, f.Return)
+	fn := c.fn
+	c.scope, c.fn, c.loops = outerScope, outerFn, outerLoops
+
+	if f.Return != nil {
+		if !env.SameType(body, f.Return) {
+			c.errorf(bodySource(f), "%s should return %s, got %s", fn.display(), f.Return.Name(), body.Name())
+		}
+	} else {
+		fn.returns.add(body, bodySource(f))
+		fnType.Return = c.common(fn.returns.types, fn.returns.sources, "returned values")
+	}
+	if f.Name != "" {
+		c.scope.declare(f.Name, fnType, true)
+	}
+	return fnType
+}
+
+// bodySource is the source of the body of f, or of f when its body is empty
+func bodySource(f ast.FunctionDecl) utils.String {
+	if s, ok := f.Body.(*ast.Scope); ok && len(s.Exprs) == 0 {
+		return f.Source()
+	}
+	return f.Body.Source()
+}
+
+func (f *function) display() string {
+	if f.name == "" {
+		return "<fn>"
+	}
+	return "<fn " + f.name + ">"
+}
+
+func (c *Checker) ret(r ast.ReturnExpr) env.Type {
+	var value env.Type = env.Nothing
+	if r.Value != nil {
+		var ret env.Type
+		if c.fn != nil {
+			ret = c.fn.ret
+		}
+		value = c.exprAs(r.Value, ret)
+	}
+	if c.fn == nil {
+		c.errorf(r.Source(), "ret outside of a function")
+		return nil
+	}
+	if c.fn.ret != nil && !env.SameType(value, c.fn.ret) {
+		c.errorf(r.Source(), "%s should return %s, got %s", c.fn.display(), c.fn.ret.Name(), value.Name())
+	}
+	c.fn.returns.add(value, r.Source())
+	return nil
+}
+
+// argument is a checked call argument
+type argument struct {
+	name   string
+	t      env.Type
+	source utils.String
+}
+
+func (c *Checker) call(n ast.CallExpr) env.Type {
+	field, ok := n.Callee.(ast.FieldExpr)
+	if !ok {
+		callee := c.resolve(c.expr(n.Callee))
+		if len(n.Args) > 0 {
+			c.mutatedArg(callee, n.Args[0].Value)
+		}
+		return c.callType(callee, c.args(n.Args, callee, 0), n.Source())
+	}
+
+	// `target.name(args)` calls a field holding a function, or `name(target, args)` with name a
+	// method of an impl or a function in scope
+	target := c.resolve(c.value(field.Target, nil))
+	switch t := target.(type) {
+	case *env.StructType:
+		if i := t.FieldIndex(field.Field); i >= 0 {
+			callee := c.resolve(t.Fields[i].Type)
+			return c.callType(callee, c.args(n.Args, callee, 0), n.Source())
+		}
+	case typeValue:
+		if _, ok := t.Type.(*env.EnumType); ok {
+			callee := c.field(target, field.Field, field)
+			return c.callType(callee, c.args(n.Args, callee, 0), n.Source())
+		}
+	case env.ModuleType:
+		callee := c.resolve(c.field(target, field.Field, field))
+		return c.callType(callee, c.args(n.Args, callee, 0), n.Source())
+	}
+	if method, ok := c.method(target, field.Field); ok {
+		args := append([]argument{{t: target, source: field.Target.Source()}}, c.args(n.Args, method, 1)...)
+		return c.callType(method, args, n.Source())
+	}
+	v, ok := c.scope.lookup(field.Field)
+	if !ok {
+		c.args(n.Args, nil, 0)
+		if target != nil {
+			c.errorf(field.Source(), "%s has no field or function named %s", target.Name(), field.Field)
+		}
+		return nil
+	}
+	callee := c.resolve(v.Type)
+	c.mutatedArg(callee, field.Target)
+	args := append([]argument{{t: target, source: field.Target.Source()}}, c.args(n.Args, callee, 1)...)
+	return c.callType(callee, args, n.Source())
+}
+
+// args checks the arguments of a call to a value of type callee, literals take the type of
+// their parameter or field, offset is the number of arguments passed before them, see ast.ArgumentTypes
+func (c *Checker) args(callArgs []ast.CallArg, callee env.Type, offset int) []argument {
+	types := c.argumentTypes(callArgs, callee, offset)
+	args := make([]argument, len(callArgs))
+	for i, arg := range callArgs {
+		var t env.Type
+		if types != nil {
+			t = types[i]
+		}
+		args[i] = argument{name: arg.Name, t: c.value(arg.Value, t), source: arg.Source()}
+	}
+	return args
+}
+
+func (c *Checker) argumentTypes(args []ast.CallArg, callee env.Type, offset int) []env.Type {
+	var params []env.Type
+	switch fn := callee.(type) {
+	case env.FunctionType:
+		// type parameters are only known once the arguments are checked
+		names := make([]string, len(args))
+		for i, arg := range args {
+			names[i] = arg.Name
+		}
+		return ast.ParamTypes(env.NewTypeBindings(fn.TypeParams).Substitute(fn).(env.FunctionType), names, offset)
+	case typeValue:
+		switch t := fn.Type.(type) {
+		case *env.StructType:
+			types := make([]env.Type, len(args))
+			for i, arg := range args {
+				if j := t.FieldIndex(arg.Name); j >= 0 {
+					types[i] = t.Fields[j].Type
+				}
+			}
+			return types
+		case *env.EnumType:
+			for _, variant := range t.Variants {
+				if variant.Name == "" && len(variant.Payload) == len(args) {
+					if params != nil {
+						return nil
+					}
+					params = variant.Payload
+				}
+			}
+			offset = 0
+		}
+	}
+	if len(params) != len(args)+offset {
+		return nil
+	}
+	return params[offset:]
+}
+
+// callType checks a call to a value of type callee and returns the type of its result
+func (c *Checker) callType(callee env.Type, args []argument, source utils.String) env.Type {
+	switch fn := callee.(type) {
+	case nil:
+		return nil
+	case typeValue:
+		switch t := fn.Type.(type) {
+		case *env.StructType:
+			return c.construct(t, args, source)
+		case *env.EnumType:
+			return c.constructVariant(t, args, source)
+		}
+	case builtin:
+		return c.callBuiltin(fn, args, source)
+	case env.FunctionType:
+		params, given, ok := c.paramArgs(fn, args, source)
+		if !ok {
+			return fn.Return
+		}
+		bindings := c.typeArgs(fn, params, given, args)
+		for i, k := range given {
+			param, arg := bindings.Substitute(params[i]), args[k]
+			if env.SameType(arg.t, param) {
+				continue
+			}
+			if arg.name != "" {
+				c.errorf(arg.source, "expected %s for argument %s, got %s", param.Name(), arg.name, arg.t.Name())
+			} else {
+				c.errorf(arg.source, "expected %s for argument %d, got %s", param.Name(), k+1, arg.t.Name())
+			}
+		}
+		return bindings.Substitute(fn.Return)
+	}
+	c.errorf(source, "cannot call a value of type %s", callee.Name())
+	return nil
+}
+
+// paramArgs pairs the arguments of a call to a function of type fn with the parameters they are
+// given to: the type of each parameter given an argument and the index of that argument, see
+// ast.MatchArgs. ok is false when the arguments do not match the parameters.
+func (c *Checker) paramArgs(fn env.FunctionType, args []argument, source utils.String) (params []env.Type, given []int, ok bool) {
+	names, sources := make([]string, len(args)), make([]utils.String, len(args))
+	for i, arg := range args {
+		names[i], sources[i] = arg.name, arg.source
+	}
+	matched, err := ast.MatchArgs(fn, names, sources, source)
+	if err != nil {
+		c.errors = append(c.errors, err.(utils.Error))
+		return nil, nil, false
+	}
+	for i, k := range matched {
+		if k < 0 {
+			continue
+		}
+		if i < len(fn.Params) {
+			params = append(params, fn.Params[i])
+		} else {
+			params = append(params, fn.Named[i-len(fn.Params)].Type)
+		}
+		given = append(given, k)
+	}
+	return params, given, true
+}
+
+// typeArgs infers the type arguments of a call of a generic function from the types of its
+// parameters given an argument and checks their bounds, see ast.bindTypeArgs
+func (c *Checker) typeArgs(fn env.FunctionType, params []env.Type, given []int, args []argument) env.TypeBindings {
+	bindings := env.NewTypeBindings(fn.TypeParams)
+	sources := map[string]utils.String{}
+	for i, k := range given {
+		arg := args[k]
+		bindings.Infer(params[i], c.resolve(arg.t))
+		for name, t := range bindings {
+			if _, ok := sources[name]; !ok && t != nil {
+				sources[name] = arg.source
+			}
+		}
+	}
+	for _, param := range fn.TypeParams {
+		t := bindings[param.Name]
+		if t == nil {
+			continue
+		}
+		for _, bound := range param.Bounds {
+			if trait, ok := c.trait(bound); ok && !implements(c.resolve(t), trait) {
+				c.errorf(sources[param.Name], "%s does not implement %s, required by %s", t.Name(), bound, param.Name)
+			}
+		}
+	}
+	return bindings
+}
+
+func (c *Checker) positional(args []argument) bool {
+	for _, arg := range args {
+		if arg.name != "" {
+			c.errorf(arg.source, "named arguments are only supported by comps and functions with named parameters")
+			return false
+		}
+	}
+	return true
+}
+
+// construct checks `Person(name: 'Anas', age: 20)`, see ast.constructStruct
+func (c *Checker) construct(t *env.StructType, args []argument, source utils.String) env.Type {
+	given := make([]bool, len(t.Fields))
+	for _, arg := range args {
+		if arg.name == "" {
+			c.errorf(arg.source, "fields of %s must be named, as in %s(field: value)", t.Name(), t.Name())
+			continue
+		}
+		i := t.FieldIndex(arg.name)
+		if i < 0 {
+			c.errorf(arg.source, "%s has no field %s", t.Name(), arg.name)
+			continue
+		}
+		if given[i] {
+			c.errorf(arg.source, "field %s is given more than once", arg.name)
+		}
+		given[i] = true
+		if !env.SameType(arg.t, t.Fields[i].Type) {
+			c.errorf(arg.source, "field %s expects %s, got %s", arg.name, t.Fields[i].Type.Name(), arg.t.Name())
+		}
+	}
+	for i, field := range t.Fields {
+		if !given[i] {
+			c.errorf(source, "missing field %s of %s", field.Name, t.Name())
+		}
+	}
+	return t
+}
+
+// constructVariant checks `Color(1, 2, 3)`, which selects an anonymous variant by the argument types
+func (c *Checker) constructVariant(t *env.EnumType, args []argument, source utils.String) env.Type {
+	if !c.positional(args) {
+		return t
+	}
+	types := make([]env.Type, len(args))
+	for i, arg := range args {
+		if arg.t == nil {
+			return t
+		}
+		types[i] = arg.t
+	}
+	if t.AnonymousVariant(types) < 0 {
+		c.errorf(source, "%s has no variant taking %s", t.Name(), env.TupleType{Elems: types}.Name())
+	}
+	return t
+}
+
+// mutatedArg checks the first argument of a builtin changing it in place, see ast.checkMutatedArg
+func (c *Checker) mutatedArg(callee env.Type, arg ast.Expr) {
+	fn, ok := callee.(builtin)
+	if !ok {
+		return
+	}
+	if b, ok := env.Builtin(fn.name); ok && b.Mutates {
+		c.mutableRoot(arg, "call "+fn.name+" on")
+	}
+}
+
+func (c *Checker) callBuiltin(fn builtin, args []argument, source utils.String) env.Type {
+	if !c.positional(args) {
+		return nil
+	}
+	if fn.name == "print" {
+		return env.Nothing
+	}
+	count := map[string]int{"len": 1, "byte_len": 1, "grapheme_len": 1, "push": 2, "pop": 1}[fn.name]
+	if len(args) != count {
+		c.errorf(source, "%s expects %d arguments, got %d", fn.name, count, len(args))
+		return nil
+	}
+	arg := c.resolve(args[0].t)
+	if arg == nil {
+		return nil
+	}
+	switch fn.name {
+	case "len":
+		switch arg.BaseType() {
+		case env.List, env.Range, env.Str:
+		default:
+			c.errorf(source, "len is not defined for %s", arg.Name())
+		}
+		return env.I32
+	case "byte_len", "grapheme_len":
+		if arg.BaseType() != env.Str {
+			c.errorf(source, "%s expects a str, got %s", fn.name, arg.Name())
+		}
+		return env.I32
+	case "push", "pop":
+		list, ok := arg.(env.ListType)
+		if !ok {
+			c.errorf(source, "%s expects a list, got %s", fn.name, arg.Name())
+			return nil
+		}
+		if fn.name == "pop" {
+			return list.Elem
+		}
+		if !env.SameType(args[1].t, list.Elem) {
+			c.errorf(args[1].source, "cannot push %s to %s", args[1].t.Name(), list.Name())
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenericBodies(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string // the error reported, empty when the program is valid
+	}{
+		{"fn f<T implements Addition>(x: T): T -> x + x", ""},
+		{"fn f<T implements Addition>(items: List<T>): T {\nmut total := T.zero\nfor item in items { total = total + item }\ntotal\n}", ""},
+		{"fn f<T implements Ordering>(a: T, b: T): T -> if a > b { a } else { b }", ""},
+		{"fn f<T implements Equality>(a: T, b: T): bool -> a != b", ""},
+		{"fn f<T>(items: List<T>, x: T): bool -> x in items", ""},
+		{"fn f<T implements Addition>(x: T): T -> x\nfn g<T implements Addition>(x: T): T -> f(x)", ""},
+		{"abs Shape {\nfn area(self) : f64\n}\nfn f<T implements Shape>(s: T): f64 -> s.area()", ""},
+		// operations the bounds do not provide
+		{"fn f<T implements Addition>(x: T) -> x - x", "cannot apply - to T and T"},
+		{"fn f<T>(x: T) -> x + x", "cannot apply + to T and T"},
+		{"fn f<T>(x: T) -> x == x", "cannot apply == to T and T"},
+		{"fn f<T implements Addition>(x: T) -> x + 1", "cannot apply + to T and i32"},
+		{"fn f<T implements Subtraction>(x: T) -> -x", "cannot apply - to T"},
+		{"fn f<T>(x: T) -> x.len", "T has no field len"},
+		{"fn f<T>(x: T) -> T.zero", "T has no constant zero"},
+		{"fn f<T implements Addition>(x: T) -> x.add(x)", "T has no field or function named add"},
+		{"fn f<T implements Addition>(x: T): T -> x\nfn g<T>(x: T) -> f(x)", "T does not implement Addition, required by T"},
+	}
+	for _, test := range tests {
+		err := check(t, test.source)
+		if test.err == "" && err != nil {
+			t.Errorf("checking %q failed: %v", test.source, err)
+		} else if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("checking %q gives %v, want %q", test.source, err, test.err)
+		}
+	}
+}
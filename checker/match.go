@@ -0,0 +1,85 @@
+package checker
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// match checks a match expression, when its value is not used its arms may have different types
+func (c *Checker) match(n ast.MatchExpr, used bool) env.Type {
+	scrutinee := c.resolve(c.value(n.Scrutinee, nil))
+	if scrutinee != nil {
+		if err := ast.CheckMatchArms(n, scrutinee); err != nil {
+			c.errors = append(c.errors, err.(utils.Error))
+		}
+	}
+	arms := make([]env.Type, len(n.Arms))
+	sources := make([]utils.String, len(n.Arms))
+	for i, arm := range n.Arms {
+		outer := c.scope
+		c.scope = newScope(outer)
+		c.bindMatchPattern(arm.Pattern, scrutinee)
+		if used {
+			arms[i] = c.expr(arm.Body)
+		} else {
+			c.statement(arm.Body)
+		}
+		sources[i] = arm.Body.Source()
+		c.scope = outer
+	}
+	if !used {
+		return nil
+	}
+	return c.common(arms, sources, "match arms")
+}
+
+// bindMatchPattern declares the names bound by p when it matches a value of type t,
+// patterns other than `none` match the T in a T?
+func (c *Checker) bindMatchPattern(p ast.MatchPattern, t env.Type) {
+	t = c.resolve(env.Unwrap(t))
+	switch p.Kind {
+	case ast.BindingPattern:
+		if p.Type != nil {
+			c.annotation(p.Type, p.Source())
+			t = p.Type
+		}
+		c.scope.declare(p.Name, t, true)
+	case ast.VariantPattern:
+		var payload []env.Type
+		if enum, ok := t.(*env.EnumType); ok {
+			if i := enum.VariantIndex(p.Name); i >= 0 {
+				payload = enum.Variants[i].Payload
+			}
+		}
+		c.bindMatchPatterns(p.Elems, payload)
+	case ast.TuplePattern:
+		var elems []env.Type
+		switch t := t.(type) {
+		case env.TupleType:
+			elems = t.Elems
+		case *env.EnumType:
+			// an untyped tuple pattern takes the payload of the only anonymous variant of its length
+			for _, variant := range t.Variants {
+				if variant.Name == "" && len(variant.Payload) == len(p.Elems) {
+					if elems != nil {
+						elems = nil
+						break
+					}
+					elems = variant.Payload
+				}
+			}
+		}
+		c.bindMatchPatterns(p.Elems, elems)
+	}
+}
+
+func (c *Checker) bindMatchPatterns(patterns []ast.MatchPattern, types []env.Type) {
+	for i, pattern := range patterns {
+		var t env.Type
+		if len(types) == len(patterns) {
+			t = c.resolve(types[i])
+		}
+		c.bindMatchPattern(pattern, t)
+	}
+}
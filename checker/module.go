@@ -0,0 +1,142 @@
+package checker
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/utils"
+)
+
+// moduleKey identifies an imported module by its path and whether it is a local import
+type moduleKey struct {
+	path  string
+	local bool
+}
+
+// DeclareModule gives the checker the type of the module imported by path, so that its uses are
+// checked. The modules that are not declared are of unknown type.
+func (c *Checker) DeclareModule(path []string, local bool, t env.ModuleType) {
+	if c.modules == nil {
+		c.modules = map[moduleKey]env.ModuleType{}
+	}
+	c.modules[moduleKey{path: strings.Join(path, "."), local: local}] = t
+}
+
+// Module returns the type of the module made of the top level declarations checked so far
+func (c *Checker) Module(name string) env.ModuleType {
+	t := env.ModuleType{ModuleName: name, Members: map[string]env.Type{}, Private: map[string]bool{}}
+	for varName, v := range c.root().vars {
+		if v.public {
+			t.Members[varName] = v.Type
+		} else {
+			t.Private[varName] = true
+		}
+	}
+	return t
+}
+
+func (c *Checker) root() *scope {
+	root := c.scope
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+// topLevel reports an error unless the current expression is at the top level of a file
+func (c *Checker) topLevel(source utils.String, what string) bool {
+	if c.scope.parent != nil {
+		c.errorf(source, "%s must be at the top level of a file", what)
+		return false
+	}
+	return true
+}
+
+// importExpr checks an import, which is declared when its scope is hoisted
+func (c *Checker) importExpr(n ast.ImportExpr) env.Type {
+	c.topLevel(n.Source(), "imports")
+	return nil
+}
+
+// declareImport declares the module or the declarations imported, see ast.ImportExpr
+func (c *Checker) declareImport(n ast.ImportExpr) {
+	module, known := c.modules[moduleKey{path: n.Module(), local: n.Local}]
+	if n.Names == nil {
+		if known {
+			c.scope.declare(n.Alias, module, true)
+		} else {
+			c.scope.declare(n.Alias, nil, true)
+		}
+		return
+	}
+	for _, name := range n.Names {
+		var t env.Type
+		if known {
+			t = c.member(module, name.Name, name.Source())
+		}
+		c.scope.declare(name.Alias, t, true)
+	}
+}
+
+// member returns the type of the public declaration name of a module, see env.ModuleValue.Member
+func (c *Checker) member(module env.ModuleType, name string, source utils.String) env.Type {
+	if t, ok := module.Members[name]; ok {
+		return t
+	}
+	if module.Private[name] {
+		c.errorf(source, "%s is private to module %s, declare it with pub", name, module.ModuleName)
+	} else {
+		c.errorf(source, "module %s has no %s", module.ModuleName, name)
+	}
+	return nil
+}
+
+// qualified resolves a type declared by an imported module, `shapes.Square`. It returns named
+// when the module does not declare it, and nil when the module is unknown.
+func (c *Checker) qualified(named env.NamedType) env.Type {
+	v, ok := c.scope.lookup(named.Module)
+	if !ok {
+		return named
+	}
+	if v.Type == nil {
+		return nil
+	}
+	module, ok := v.Type.(env.ModuleType)
+	if !ok {
+		return named
+	}
+	if declared, ok := module.Members[named.TypeName].(typeValue); ok {
+		return declared.Type
+	}
+	return named
+}
+
+// qualifiedError reports why a type of an imported module could not be resolved
+func (c *Checker) qualifiedError(named env.NamedType, source utils.String) {
+	v, _ := c.scope.lookup(named.Module)
+	module, ok := v.Type.(env.ModuleType)
+	if !ok {
+		c.errorf(source, "%s is not a module", named.Module)
+		return
+	}
+	if _, ok := module.Members[named.TypeName]; ok {
+		c.errorf(source, "%s.%s is not a type", named.Module, named.TypeName)
+		return
+	}
+	c.member(module, named.TypeName, source)
+}
+
+// pub checks a public declaration, only the top level declarations of a file can be imported
+func (c *Checker) pub(n ast.PubDecl) env.Type {
+	t := c.expr(n.Decl)
+	if !c.topLevel(n.Source(), "pub declarations") {
+		return t
+	}
+	name, _ := ast.DeclaredName(n.Decl)
+	if v, ok := c.scope.vars[name]; ok {
+		v.public = true
+		c.scope.vars[name] = v
+	}
+	return t
+}
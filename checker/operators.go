@@ -0,0 +1,218 @@
+package checker
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+func isNumeric(t env.Type) bool {
+	return t.BaseType() <= env.F64
+}
+
+func isInteger(t env.Type) bool {
+	return t.BaseType().IsInteger()
+}
+
+func isUnsigned(t env.Type) bool {
+	return t.BaseType() >= env.U8 && t.BaseType() <= env.U64
+}
+
+func isFloat(t env.Type) bool {
+	return t.BaseType().IsFloat()
+}
+
+func isBool(t env.Type) bool {
+	return t.BaseType() == env.Bool
+}
+
+// isOrdered reports whether values of type t can be compared with < and >
+func isOrdered(t env.Type) bool {
+	return isNumeric(t) || t.BaseType() == env.Char
+}
+
+func isAddable(t env.Type) bool {
+	return isNumeric(t) || t.BaseType() == env.Str
+}
+
+func isGeneric(t env.Type) bool {
+	_, ok := t.(generic)
+	return ok
+}
+
+// binaryExpr checks both operands, an untyped number literal takes the type of the other one, see ast.BinaryExpr,
+// and both are expected to have type t when the result of the operator has the type of its operands
+func (c *Checker) binaryExpr(n ast.BinaryExpr, t env.Type) env.Type {
+	if t = c.resolve(t); !ast.PreservesType(n.Op) || t == nil || !isNumeric(t) {
+		t = nil
+	}
+	var left, right env.Type
+	if !independentOperands(n.Op) && ast.TypedFromRight(*n.Left, *n.Right) {
+		right = c.value(*n.Right, t)
+		left = c.value(*n.Left, c.operandType(n.Op, right))
+	} else {
+		left = c.value(*n.Left, t)
+		right = c.value(*n.Right, c.operandType(n.Op, left))
+	}
+	c.divisor(n.Op, *n.Right, n.Source())
+	return c.binary(n.Op, left, right, n.Source())
+}
+
+// divisor reports integer divisions and modulos by a literal zero, which would fail at runtime
+func (c *Checker) divisor(op lexer.TokenType, expr ast.Expr, source utils.String) {
+	if op != lexer.DIVIDE && op != lexer.MODULO {
+		return
+	}
+	literal, ok := expr.(ast.Literal)
+	if !ok {
+		return
+	}
+	if i, ok := env.BigIntValue(literal.Value); ok && i.Sign() == 0 {
+		if op == lexer.DIVIDE {
+			c.errorf(source, "division by zero")
+		} else {
+			c.errorf(source, "modulo by zero")
+		}
+	}
+}
+
+func independentOperands(op lexer.TokenType) bool {
+	return op == lexer.BITWISE_LEFT_SHIFT || op == lexer.BITWISE_RIGHT_SHIFT || op == lexer.POWER
+}
+
+// operandType is the type an untyped literal takes next to an operand of type other, see ast.OperandType
+func (c *Checker) operandType(op lexer.TokenType, other env.Type) env.Type {
+	if other != nil && independentOperands(op) && isInteger(other) {
+		return env.U32
+	}
+	switch o := c.resolve(other).(type) {
+	case env.RangeType:
+		if op == lexer.IN || op == lexer.RANGE {
+			return o.Elem
+		}
+	case env.ListType:
+		if op == lexer.IN {
+			return o.Elem
+		}
+	}
+	return other
+}
+
+// binary returns the type of `left op right`, mirroring the rules of AddValues and friends
+func (c *Checker) binary(op lexer.TokenType, left, right env.Type, source utils.String) env.Type {
+	left, right = c.resolve(left), c.resolve(right)
+	if t, ok := c.overloaded(op, left, right, source); ok {
+		return t
+	}
+	if isGeneric(left) || isGeneric(right) {
+		// the operators of a type parameter are those of its bounds, found above, it can still be looked for in a list
+		if op != lexer.IN || isGeneric(right) {
+			c.operandError(op, left, right, source)
+			return nil
+		}
+	}
+	switch op {
+	case lexer.PLUS:
+		return c.sameOperands(op, left, right, source, isAddable)
+	case lexer.MINUS, lexer.MULTIPLY, lexer.DIVIDE, lexer.MODULO:
+		return c.sameOperands(op, left, right, source, isNumeric)
+	case lexer.BITWISE_AND, lexer.BITWISE_OR, lexer.BITWISE_XOR:
+		return c.sameOperands(op, left, right, source, isInteger)
+	case lexer.BITWISE_LEFT_SHIFT, lexer.BITWISE_RIGHT_SHIFT:
+		if (left != nil && !isInteger(left)) || (right != nil && !isUnsigned(right)) {
+			c.operandError(op, left, right, source)
+			return nil
+		}
+		return left
+	case lexer.POWER:
+		if left == nil || right == nil {
+			return left
+		}
+		if (isInteger(left) && isUnsigned(right)) || (isFloat(left) && (isInteger(right) || right.BaseType() == left.BaseType())) {
+			return left
+		}
+		c.operandError(op, left, right, source)
+		return nil
+	case lexer.GREATER_THAN, lexer.GREATER_THAN_OR_EQUAL, lexer.LESS_THAN, lexer.LESS_THAN_OR_EQUAL:
+		c.sameOperands(op, left, right, source, isOrdered)
+		return env.Bool
+	case lexer.AND, lexer.OR:
+		c.sameOperands(op, left, right, source, isBool)
+		return env.Bool
+	case lexer.EQUAL, lexer.NOT_EQUAL:
+		// a T? is compared with a T or none
+		if !env.SameType(left, right) && !env.SameType(right, left) {
+			c.operandError(op, left, right, source)
+		}
+		return env.Bool
+	case lexer.RANGE, lexer.RANGE_INCLUSIVE:
+		if r, ok := left.(env.RangeType); ok && op == lexer.RANGE {
+			// `0..10..2` adds a step to the range
+			if right != nil && right.BaseType() != r.Elem {
+				c.errorf(source, "range step must be %s, got %s", r.Elem.Name(), right.Name())
+			}
+			return r
+		}
+		elem := c.sameOperands(op, left, right, source, isInteger)
+		if elem == nil {
+			return nil
+		}
+		return env.RangeType{Elem: elem.BaseType()}
+	case lexer.IN:
+		switch container := right.(type) {
+		case env.RangeType:
+			if left != nil && left.BaseType() != container.Elem {
+				c.operandError(op, left, right, source)
+			}
+		case env.ListType:
+			if !env.SameType(left, container.Elem) {
+				c.operandError(op, left, right, source)
+			}
+		case nil:
+		default:
+			c.operandError(op, left, right, source)
+		}
+		return env.Bool
+	}
+	return nil
+}
+
+// sameOperands checks that both operands share one type accepted by ok and returns it
+func (c *Checker) sameOperands(op lexer.TokenType, left, right env.Type, source utils.String, ok func(env.Type) bool) env.Type {
+	if !env.SameType(left, right) || (left != nil && !ok(left)) || (right != nil && !ok(right)) {
+		c.operandError(op, left, right, source)
+		return nil
+	}
+	if left != nil {
+		return left
+	}
+	return right
+}
+
+func (c *Checker) operandError(op lexer.TokenType, left, right env.Type, source utils.String) {
+	c.errorf(source, "cannot apply %s to %s and %s", ast.OperatorSymbols[op], typeName(left), typeName(right))
+}
+
+// unary returns the type of `op value`, mirroring Minus, Plus, Not and BitwiseNot
+func (c *Checker) unary(op lexer.TokenType, value env.Type, source utils.String) env.Type {
+	if value == nil {
+		return nil
+	}
+	var ok bool
+	switch op {
+	case lexer.MINUS, lexer.PLUS:
+		ok = isNumeric(value)
+	case lexer.NOT:
+		ok = isBool(value)
+	case lexer.BITWISE_NOT:
+		ok = isInteger(value)
+	default:
+		return nil
+	}
+	if !ok {
+		c.errorf(source, "cannot apply %s to %s", ast.OperatorSymbols[op], value.Name())
+		return nil
+	}
+	return value
+}
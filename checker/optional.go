@@ -0,0 +1,104 @@
+package checker
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+)
+
+// isNone checks `x is none`, only optionals can be none
+func (c *Checker) isNone(n ast.IsNoneExpr) env.Type {
+	t := c.resolve(c.value(n.Value, nil))
+	if _, ok := t.(env.OptionalType); t != nil && !ok {
+		c.errorf(n.Value.Source(), "only optionals can be none, got %s", t.Name())
+	}
+	return env.Bool
+}
+
+// narrowing is a T? variable known to hold a T, `x` in `if x is not none { x + 1 }`
+type narrowing struct {
+	name string
+	v    variable
+}
+
+// narrowings returns the variable a condition tests against none, narrowed in the branch taken when
+// the condition holds or in the other one, both are nil when the condition narrows nothing. A variable
+// assigned by a function is not narrowed, as calling the function could set it back to none.
+func (c *Checker) narrowings(condition ast.Expr) (then, otherwise *narrowing) {
+	test, ok := condition.(ast.IsNoneExpr)
+	if !ok {
+		return nil, nil
+	}
+	id, ok := test.Value.(ast.Identifier)
+	if !ok {
+		return nil, nil
+	}
+	v, ok := c.scope.lookup(id.Name())
+	if !ok || (c.captured[id.Name()] && !v.Const) {
+		return nil, nil
+	}
+	optional, ok := c.resolve(v.Type).(env.OptionalType)
+	if !ok || optional.Elem == nil {
+		return nil, nil
+	}
+	n := &narrowing{name: id.Name(), v: variable{Type: optional.Elem, Const: v.Const, optional: v.Type}}
+	if test.Negated {
+		return n, nil
+	}
+	return nil, n
+}
+
+// narrowed checks a branch with the variable of n narrowed, n may be nil
+func (c *Checker) narrowed(n *narrowing, check func() env.Type) env.Type {
+	if n == nil {
+		return check()
+	}
+	outer := c.scope
+	c.scope = newScope(outer)
+	defer func() { c.scope = outer }()
+	c.scope.vars[n.name] = n.v
+	return check()
+}
+
+// narrowAfter narrows a variable for the rest of the current scope after a conditional
+// leaving it whenever the variable is none, as in `if x is none { ret 0 }`
+func (c *Checker) narrowAfter(expr ast.Expr) {
+	n, ok := expr.(ast.ConditionalExpr)
+	if !ok || n.Next != nil || len(n.Content.Exprs) == 0 {
+		return
+	}
+	switch n.Content.Exprs[len(n.Content.Exprs)-1].(type) {
+	case ast.ReturnExpr, ast.BreakExpr, ast.ContinueExpr:
+		if _, otherwise := c.narrowings(n.Condition); otherwise != nil {
+			c.scope.vars[otherwise.name] = otherwise.v
+		}
+	}
+}
+
+// widen gives their optional type back to the narrowed variables among names, which may be set to
+// none, for the rest of the scope narrowing them
+func (c *Checker) widen(names map[string]bool) {
+	for name := range names {
+		if v, ok := c.scope.lookup(name); ok && v.optional != nil {
+			c.scope.update(name, widened(v))
+		}
+	}
+}
+
+// widenMutable hides the narrowings of mutable variables from a function body, which may be called
+// after the variables are set back to none
+func (c *Checker) widenMutable() {
+	seen := map[string]bool{}
+	for outer := c.scope.parent; outer != nil; outer = outer.parent {
+		for name, v := range outer.vars {
+			if !seen[name] && v.optional != nil && !v.Const {
+				c.scope.vars[name] = widened(v)
+			}
+			seen[name] = true
+		}
+	}
+}
+
+func widened(v variable) variable {
+	v.Type, v.optional = v.optional, nil
+	return v
+}
@@ -0,0 +1,55 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+
+	"com.loop.anonx3247/parser"
+)
+
+// check parses and checks source, failing the test when it does not parse
+func check(t *testing.T, source string) error {
+	t.Helper()
+	p, err := parser.NewParser(source)
+	if err != nil {
+		t.Fatalf("lexing %q failed: %v", source, err)
+	}
+	program, err := p.Parse()
+	if err != nil {
+		t.Fatalf("parsing %q failed: %v", source, err)
+	}
+	return Check(&program)
+}
+
+func TestNarrowing(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string // the error reported, empty when the program is valid
+	}{
+		{"x : i32? = 3\nif x is not none { x + 1 }", ""},
+		{"x : i32? = 3\nif x is none { 0 } else { x + 1 }", ""},
+		{"x : i32? = 3\nx + 1", "cannot apply + to i32? and i32"},
+		{"fn f(x: i32?): i32 {\nif x is none { ret 0 }\nx + 1\n}", ""},
+		// assignments
+		{"mut x : i32? = 3\nif x is not none {\nx = 4\nx += 1\nx + 1\n}", ""},
+		{"mut x : i32? = 3\nif x is not none {\nx = none\nx + 1\n}", "cannot apply + to i32? and i32"},
+		{"mut x : i32? = 3\nif x is not none {\nif true { x = none }\nx + 1\n}", "cannot apply + to i32? and i32"},
+		{"mut x : i32? = 3\nif x is not none {\nloop {\nx + 1\nx = none\n}\n}", "cannot apply + to i32? and i32"},
+		{"mut x : i32? = 3\nif x is not none {\nwhile x > 0 { x = none }\n}", "cannot apply > to i32? and i32"},
+		// functions assigning a variable
+		{"mut x : i32? = 3\nf := fn () { x = none }\nif x is not none {\nf()\nx + 1\n}", "cannot apply + to i32? and i32"},
+		{"mut x : i32? = 3\nfn h() {\nif x is not none {\ng()\nx + 1\n}\n}\nfn g() { x = none }", "cannot apply + to i32? and i32"},
+		{"mut x : i32? = 3\nfn g() {\nmut x := 0\nx = 1\n}\nif x is not none {\ng()\nx + 1\n}", ""},
+		// functions reading a variable after it is set back to none
+		{"mut x : i32? = 3\nif x is not none { f := fn (): i32 -> x + 1 }", "cannot apply + to i32? and i32"},
+		{"x : i32? = 3\nif x is not none { f := fn (): i32 -> x + 1 }", ""},
+	}
+	for _, test := range tests {
+		err := check(t, test.source)
+		if test.err == "" && err != nil {
+			t.Errorf("checking %q failed: %v", test.source, err)
+		} else if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("checking %q gives %v, want %q", test.source, err, test.err)
+		}
+	}
+}
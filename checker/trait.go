@@ -0,0 +1,165 @@
+package checker
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// abs checks the annotations of a trait, in which Self stands for any implementing type
+func (c *Checker) abs(n ast.AbsDecl) env.Type {
+	outer := c.scope
+	c.scope = newScope(outer)
+	c.scope.declare(env.Self.TypeName, typeParam{generic{param: env.TypeParam{Name: env.Self.TypeName}}}, true)
+	for _, member := range append(append([]env.Field{}, n.Methods...), n.Constants...) {
+		c.annotation(member.Type, n.Source())
+	}
+	c.scope = outer
+	v, _ := c.scope.lookup(n.Name)
+	return v.Type
+}
+
+// implementing resolves the trait and the type of an impl, ok is false when either is invalid
+func (c *Checker) implementing(n ast.ImplDecl) (trait *env.TraitType, forType env.Type, err error) {
+	trait, ok := c.trait(n.Trait)
+	if !ok {
+		return nil, nil, utils.Error{Source: n.Source(), Message: "unknown trait " + n.Trait}
+	}
+	forType, err = ast.ImplementingType(c.resolve(n.For), trait, n.Source())
+	return trait, forType, err
+}
+
+// hoistImpl records an impl before the expressions of its scope are checked, so that methods and
+// operators can be used by the functions declared before it. Its errors are reported by impl.
+func (c *Checker) hoistImpl(n ast.ImplDecl) {
+	trait, forType, err := c.implementing(n)
+	if err != nil {
+		return
+	}
+	if !env.Implement(&env.Impl{Trait: trait, For: forType}) {
+		c.errorf(n.Source(), "%s already implements %s", forType.Name(), trait.TraitName)
+	}
+}
+
+// impl checks that the methods and constants of an impl match its trait, see ast.ImplDecl
+func (c *Checker) impl(n ast.ImplDecl) env.Type {
+	if named, ok := n.For.(env.NamedType); ok {
+		if _, ok := c.resolve(named).(env.NamedType); ok {
+			c.errorf(n.Source(), "unknown type %s", named.TypeName)
+			return nil
+		}
+	}
+	trait, forType, err := c.implementing(n)
+	if err != nil {
+		c.errors = append(c.errors, err.(utils.Error))
+		return nil
+	}
+
+	outer := c.scope
+	c.scope = newScope(outer)
+	defer func() { c.scope = outer }()
+	c.scope.declare(env.Self.TypeName, typeValue{forType}, true)
+	methods, constants := map[string]bool{}, map[string]bool{}
+	for _, method := range n.Methods {
+		methods[method.Name] = true
+		got := c.functionDecl(method.WithSelf(forType)).(env.FunctionType)
+		required, ok := trait.MethodType(method.Name, forType)
+		if !ok {
+			c.errorf(method.Source(), "%s is not a method of %s", method.Name, trait.TraitName)
+		} else if !sameSignature(got, required) {
+			c.errorf(method.Source(), "%s of %s must be %s, got %s", method.Name, trait.TraitName, required.Name(), got.Name())
+		}
+	}
+	for _, constant := range n.Constants {
+		constants[constant.Name] = true
+		t, ok := trait.ConstantType(constant.Name, forType)
+		value := c.value(constant.Value, t)
+		if !ok {
+			c.errorf(constant.Source(), "%s is not a constant of %s", constant.Name, trait.TraitName)
+		} else if !env.SameType(value, t) {
+			c.errorf(constant.Value.Source(), "expected %s for %s, got %s", t.Name(), constant.Name, typeName(value))
+		}
+	}
+	if err := ast.MissingMembers(trait, methods, constants, n.Source()); err != nil {
+		c.errors = append(c.errors, err.(utils.Error))
+	}
+	return nil
+}
+
+// sameSignature reports whether a method of type got can implement one of type required, a
+// method whose return type is not declared by its trait may return anything
+func sameSignature(got, required env.FunctionType) bool {
+	if required.Return == nil {
+		got.Return = nil
+	}
+	return env.SameType(got, required) && env.SameType(required, got)
+}
+
+// traits returns the traits visible from the current scope, see env.Env.Traits
+func (c *Checker) traits() []*env.TraitType {
+	traits := []*env.TraitType{}
+	for current := c.scope; current != nil; current = current.parent {
+		for _, v := range current.vars {
+			if declared, ok := v.Type.(typeValue); ok {
+				if trait, ok := declared.Type.(*env.TraitType); ok {
+					traits = append(traits, trait)
+				}
+			}
+		}
+	}
+	return traits
+}
+
+// method returns the type of the method name given to values of type t by an impl, see
+// env.Env.Method
+func (c *Checker) method(t env.Type, name string) (env.FunctionType, bool) {
+	if t == nil {
+		return env.FunctionType{}, false
+	}
+	if g, ok := t.(generic); ok {
+		// the methods of the built-in traits are only called through their operators
+		for _, trait := range g.traits {
+			if method, ok := trait.MethodType(name, g); ok && !trait.IsBuiltin() {
+				return method, true
+			}
+		}
+		return env.FunctionType{}, false
+	}
+	impls := env.Impls(t)
+	if impls == nil {
+		for _, trait := range c.traits() {
+			if impl, ok := env.FindImpl(t, trait); ok {
+				impls = append(impls, impl)
+			}
+		}
+	}
+	for _, impl := range impls {
+		if method, ok := impl.Trait.MethodType(name, impl.For); ok {
+			return method, true
+		}
+	}
+	return env.FunctionType{}, false
+}
+
+// overloaded returns the type of `left op right` when left is a comp or an enum implementing the
+// built-in trait of op, see ast.OperatorTraits
+func (c *Checker) overloaded(op lexer.TokenType, left, right env.Type, source utils.String) (env.Type, bool) {
+	name, ok := ast.OperatorTraits[op]
+	if !ok || left == nil {
+		return nil, false
+	}
+	trait := env.BuiltinTrait(name)
+	if g, ok := left.(generic); ok {
+		if !g.implements(trait) {
+			return nil, false
+		}
+	} else if _, ok := env.FindImpl(left, trait); !ok {
+		return nil, false
+	}
+	method, _ := trait.MethodType(trait.Methods[0].Name, left)
+	if !env.SameType(right, method.Params[1]) {
+		c.operandError(op, left, right, source)
+	}
+	return method.Return, true
+}
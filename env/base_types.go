@@ -1,9 +1,13 @@
 package env
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"com.loop.anonx3247/lexer"
 	"com.loop.anonx3247/utils"
@@ -24,9 +28,91 @@ const (
 	F64
 	Bool
 	Str
+	Char
+
+	// compound kinds, values of these types are not base values
+	Function
+	Range
+	List
+	Tuple
+	Struct
+	Enum
+	Optional
+	Trait
+	Module
+	Named
+
+	// Nothing is the type of expressions without a value, such as loops and calls of functions
+	// returning nothing, the checker tells them apart from expressions of unknown type
+	Nothing
 )
 
-func ToBaseType[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool](t T) BaseType {
+var baseTypeNames = map[BaseType]string{
+	I8:       "i8",
+	I16:      "i16",
+	I32:      "i32",
+	I64:      "i64",
+	U8:       "u8",
+	U16:      "u16",
+	U32:      "u32",
+	U64:      "u64",
+	F32:      "f32",
+	F64:      "f64",
+	Bool:     "bool",
+	Str:      "str",
+	Char:     "char",
+	Function: "fn",
+	Range:    "Range",
+	List:     "List",
+	Tuple:    "tuple",
+	Struct:   "comp",
+	Enum:     "enum",
+	Nothing:  "nothing",
+}
+
+// type names that are spelled as identifiers rather than type keywords
+var baseTypeAliases = map[string]BaseType{
+	"str":  Str,
+	"int":  I32,
+	"uint": U32,
+}
+
+// BaseType doubles as the Type of every base value
+func (b BaseType) BaseType() BaseType {
+	return b
+}
+
+func (b BaseType) Name() string {
+	if name, ok := baseTypeNames[b]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// BaseTypeFromName resolves a type keyword or one of its aliases (`str`, `int`, `uint`)
+func BaseTypeFromName(name string) (BaseType, bool) {
+	if t, ok := baseTypeAliases[name]; ok {
+		return t, true
+	}
+	if name == "string" {
+		return Str, true
+	}
+	for t, n := range baseTypeNames {
+		if n == name && t < Function {
+			return t, true
+		}
+	}
+	return -1, false
+}
+
+// CodePoint is a Unicode code point, the value of a char. It is distinct from int32 so that chars
+// and i32 values are told apart.
+type CodePoint rune
+
+func ToBaseType[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | CodePoint](t T) BaseType {
+	if _, ok := any(t).(CodePoint); ok {
+		return Char
+	}
 	switch reflect.TypeOf(t).Kind() {
 	case reflect.Int8:
 		return I8
@@ -61,12 +147,12 @@ func ToBaseType[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint6
 }
 
 // Generic base value type that implements both Value and Type interfaces
-type BaseValue[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool] struct {
+type BaseValue[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | CodePoint] struct {
 	source utils.String
 	value  T
 }
 
-func NewBaseValue[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool](value T, source utils.String) BaseValue[T] {
+func NewBaseValue[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | CodePoint](value T, source utils.String) BaseValue[T] {
 	return BaseValue[T]{value: value, source: source}
 }
 
@@ -74,7 +160,7 @@ func (bv BaseValue[T]) BaseType() BaseType {
 	return ToBaseType(bv.value)
 }
 
-func HaveSameType[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool](values ...BaseValue[T]) bool {
+func HaveSameType[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | CodePoint](values ...BaseValue[T]) bool {
 	for _, value := range values {
 		if value.BaseType() != values[0].BaseType() {
 			return false
@@ -83,7 +169,7 @@ func HaveSameType[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uin
 	return true
 }
 
-func GetBaseTypeValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool](values ...Value) (out []T) {
+func GetBaseTypeValues[T int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool | CodePoint](values ...Value) (out []T) {
 	underlyingValues := make([]BaseValue[T], 0)
 	for _, value := range values {
 		val, ok := value.(BaseValue[T])
@@ -114,7 +200,18 @@ func (bv BaseValue[T]) Type() Type {
 	return bv
 }
 
+func (bv BaseValue[T]) Name() string {
+	return bv.BaseType().Name()
+}
+
 func (bv BaseValue[T]) String() string {
+	if c, ok := any(bv.value).(CodePoint); ok {
+		return string(rune(c))
+	}
+	// infinities and nan are written as their literals
+	if f, ok := FloatValue(bv); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return strings.ToLower(strings.TrimPrefix(fmt.Sprint(f), "+"))
+	}
 	return fmt.Sprintf("%v", bv.value)
 }
 
@@ -172,6 +269,10 @@ func NewStrValue(v string, source utils.String) BaseValue[string] {
 	return BaseValue[string]{value: v, source: source}
 }
 
+func NewCharValue(v rune, source utils.String) BaseValue[CodePoint] {
+	return BaseValue[CodePoint]{value: CodePoint(v), source: source}
+}
+
 func TryIntFrom[T int8 | int16 | int32 | int64 | uint16 | uint32 | uint64](tok lexer.Token) (BaseValue[T], error) {
 	if tok.Type == lexer.NUMBER_LITERAL {
 		val, ok := strconv.ParseInt(tok.Value.String(), 10, 64)
@@ -202,9 +303,192 @@ func TryBoolFrom(tok lexer.Token) (BaseValue[bool], error) {
 	}
 	return BaseValue[bool]{}, tok.Error("cannot convert token value to target type")
 }
+
+// TryStrFrom decodes a string literal, or a part of an interpolated string, without its delimiters.
+// Raw strings are taken as written, escapes are decoded in the others, see decodeEscapes.
 func TryStrFrom(tok lexer.Token) (BaseValue[string], error) {
-	if tok.Type == lexer.STRING_LITERAL {
-		return BaseValue[string]{value: tok.Value.String()}, nil
+	switch tok.Type {
+	case lexer.STRING_LITERAL, lexer.STRING_HEAD, lexer.STRING_MIDDLE, lexer.STRING_TAIL:
+		text := utils.String{Ptr: tok.Value.Ptr, Start: tok.Value.Start + 1, Length: tok.Value.Length - 2}
+		if tok.Value.String()[0] == '`' {
+			return NewStrValue(text.String(), tok.Value), nil
+		}
+		value, err := decodeEscapes(text)
+		if err != nil {
+			return BaseValue[string]{}, err
+		}
+		return NewStrValue(value, tok.Value), nil
 	}
 	return BaseValue[string]{}, utils.Error{Source: tok.Value, Message: "cannot convert token value to target type"}
 }
+
+// IsInteger reports whether b is one of the signed or unsigned integer types
+func (b BaseType) IsInteger() bool {
+	return b >= I8 && b <= U64
+}
+
+// IntValue extracts the value of any integer base value as an int64, a u64 above the largest i64
+// keeps its bits and reads as negative. Use BigIntValue where the exact value matters.
+func IntValue(v Value) (int64, bool) {
+	switch val := v.(type) {
+	case BaseValue[int8]:
+		return int64(val.value), true
+	case BaseValue[int16]:
+		return int64(val.value), true
+	case BaseValue[int32]:
+		return int64(val.value), true
+	case BaseValue[int64]:
+		return val.value, true
+	case BaseValue[uint8]:
+		return int64(val.value), true
+	case BaseValue[uint16]:
+		return int64(val.value), true
+	case BaseValue[uint32]:
+		return int64(val.value), true
+	case BaseValue[uint64]:
+		return int64(val.value), true
+	}
+	return 0, false
+}
+
+// NewIntValue builds an integer base value of type t, truncating v to the width of t
+func NewIntValue(t BaseType, v int64, source utils.String) Value {
+	switch t {
+	case I8:
+		return NewI8Value(int8(v), source)
+	case I16:
+		return NewI16Value(int16(v), source)
+	case I32:
+		return NewI32Value(int32(v), source)
+	case I64:
+		return NewI64Value(v, source)
+	case U8:
+		return NewU8Value(uint8(v), source)
+	case U16:
+		return NewU16Value(uint16(v), source)
+	case U32:
+		return NewU32Value(uint32(v), source)
+	case U64:
+		return NewU64Value(uint64(v), source)
+	}
+	return nil
+}
+
+// IsFloat reports whether b is f32 or f64
+func (b BaseType) IsFloat() bool {
+	return b == F32 || b == F64
+}
+
+var integerBits = map[BaseType]int{I8: 8, I16: 16, I32: 32, I64: 64, U8: 8, U16: 16, U32: 32, U64: 64}
+
+var numberSuffixes = []BaseType{I8, I16, I32, I64, U8, U16, U32, U64, F32, F64}
+
+// SplitNumberLiteral separates the digits of a number literal from its type suffix, as in `1_000i64`.
+// Underscores between digits are dropped, typed is false when the literal has no suffix. Literals with
+// a base prefix only take integer suffixes, the f of `0x1f32` is a digit.
+func SplitNumberLiteral(text string) (digits string, t BaseType, typed bool) {
+	digits = strings.ReplaceAll(text, "_", "")
+	_, _, prefixed := integerBase(digits)
+	for _, suffix := range numberSuffixes {
+		if strings.HasSuffix(digits, suffix.Name()) && !(prefixed && suffix.IsFloat()) {
+			return strings.TrimSuffix(digits, suffix.Name()), suffix, true
+		}
+	}
+	return digits, -1, false
+}
+
+// integerBase splits the digits of a number literal from its base prefix, `-0xff` is `-ff` in base 16
+func integerBase(digits string) (string, int, bool) {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(digits, prefix) {
+			return sign + digits[2:], base, true
+		}
+	}
+	return sign + digits, 10, false
+}
+
+// IsFloatLiteral reports whether a number literal is written as a float, `1.5`, `1e3`, `inf` or `nan`
+func IsFloatLiteral(text string) bool {
+	digits, _, _ := SplitNumberLiteral(text)
+	if _, _, prefixed := integerBase(digits); prefixed {
+		return false
+	}
+	digits = strings.TrimPrefix(digits, "-")
+	return strings.ContainsAny(digits, ".eE") || digits == "inf" || digits == "nan"
+}
+
+// NumberLiteral builds the value of a number literal. Literals with a suffix have the type of
+// their suffix, others are i32 or f32 unless they are too large, then i64, u64 or f64 are used.
+func NumberLiteral(text string, source utils.String) (Value, error) {
+	_, t, typed := SplitNumberLiteral(text)
+	if typed {
+		return NumberFromLiteral(text, t, source)
+	}
+	candidates := []BaseType{I32, I64, U64}
+	if IsFloatLiteral(text) {
+		candidates = []BaseType{F32, F64}
+	}
+	var err error
+	for _, candidate := range candidates {
+		var value Value
+		if value, err = NumberFromLiteral(text, candidate, source); err == nil {
+			return value, nil
+		}
+	}
+	return nil, err
+}
+
+// NumberFromLiteral parses the text of a number literal as a value of the numeric type t,
+// literals that are not valid for t or do not fit in it are errors
+func NumberFromLiteral(text string, t BaseType, source utils.String) (Value, error) {
+	digits, _, _ := SplitNumberLiteral(text)
+	integer, base, prefixed := integerBase(digits)
+	switch {
+	case t.IsFloat() && prefixed:
+		i, _ := new(big.Int).SetString(integer, base)
+		f, _ := new(big.Float).SetInt(i).Float64()
+		return convertFloat(f, t, Checked, source)
+	case t.IsFloat():
+		bits := 64
+		if t == F32 {
+			bits = 32
+		}
+		// ParseFloat reads -inf but not -nan, so the sign of a negated literal is applied after parsing
+		unsigned := strings.TrimPrefix(digits, "-")
+		v, err := strconv.ParseFloat(unsigned, bits)
+		if err != nil {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("literal %s does not fit in %s", text, t.Name())}
+		}
+		if unsigned != digits {
+			v = -v
+		}
+		if t == F32 {
+			return NewF32Value(float32(v), source), nil
+		}
+		return NewF64Value(v, source), nil
+	case t >= U8 && t <= U64:
+		v, err := strconv.ParseUint(integer, base, integerBits[t])
+		if err != nil {
+			return nil, integerLiteralError(text, t, err, source)
+		}
+		return NewIntValue(t, int64(v), source), nil
+	case t.IsInteger():
+		v, err := strconv.ParseInt(integer, base, integerBits[t])
+		if err != nil {
+			return nil, integerLiteralError(text, t, err, source)
+		}
+		return NewIntValue(t, v, source), nil
+	}
+	return nil, utils.Error{Source: source, Message: fmt.Sprintf("a number literal cannot be used as %s", t.Name())}
+}
+
+func integerLiteralError(text string, t BaseType, err error, source utils.String) error {
+	if errors.Is(err, strconv.ErrRange) || strings.HasPrefix(text, "-") {
+		return utils.Error{Source: source, Message: fmt.Sprintf("literal %s does not fit in %s", text, t.Name())}
+	}
+	return utils.Error{Source: source, Message: fmt.Sprintf("literal %s is not an integer, expected %s", text, t.Name())}
+}
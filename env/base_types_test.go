@@ -0,0 +1,47 @@
+package env
+
+import (
+	"math"
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+func TestNumberFromLiteralFloats(t *testing.T) {
+	tests := []struct {
+		text string
+		t    BaseType
+		want string
+	}{
+		{"1.5", F64, "1.5"},
+		{"-1.5", F64, "-1.5"},
+		{"-2.5", F32, "-2.5"},
+		{"inf", F64, "inf"},
+		{"-inf", F64, "-inf"},
+		{"-inf", F32, "-inf"},
+		{"nan", F64, "nan"},
+		{"-nan", F64, "nan"},
+		{"-nan", F32, "nan"},
+		{"-0x10", F64, "-16"},
+	}
+	for _, test := range tests {
+		v, err := NumberFromLiteral(test.text, test.t, utils.String{})
+		if err != nil {
+			t.Errorf("NumberFromLiteral(%q, %s) failed: %v", test.text, test.t.Name(), err)
+			continue
+		}
+		if v.String() != test.want || v.Type().BaseType() != test.t {
+			t.Errorf("NumberFromLiteral(%q, %s) = %s of type %s, want %s", test.text, test.t.Name(), v, v.Type().Name(), test.want)
+		}
+	}
+	isNaN := func(v Value) bool {
+		f, ok := FloatValue(v)
+		return ok && math.IsNaN(f)
+	}
+	if v, err := NumberFromLiteral("-nan", F64, utils.String{}); err != nil || !isNaN(v) {
+		t.Errorf("-nan is %v, %v, want nan", v, err)
+	}
+	if _, err := NumberFromLiteral("-1e400", F64, utils.String{}); err == nil || err.(utils.Error).Message != "literal -1e400 does not fit in f64" {
+		t.Errorf("-1e400 gives %v, want it not to fit in f64", err)
+	}
+}
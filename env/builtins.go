@@ -0,0 +1,142 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"com.loop.anonx3247/utils"
+)
+
+var builtins = []*BuiltinFunction{
+	{Name: "print", Call: builtinPrint},
+	{Name: "len", Call: builtinLen},
+	{Name: "byte_len", Call: builtinByteLen},
+	{Name: "grapheme_len", Call: builtinGraphemeLen},
+	{Name: "push", Mutates: true, Call: builtinPush},
+	{Name: "pop", Mutates: true, Call: builtinPop},
+}
+
+// Builtin returns the builtin function called name
+func Builtin(name string) (*BuiltinFunction, bool) {
+	for _, builtin := range builtins {
+		if builtin.Name == name {
+			return builtin, true
+		}
+	}
+	return nil, false
+}
+
+// BuiltinNames returns the names of the builtin functions every program can call
+func BuiltinNames() []string {
+	names := make([]string, len(builtins))
+	for i, builtin := range builtins {
+		names[i] = builtin.Name
+	}
+	return names
+}
+
+func defineBuiltins(e *Env) {
+	for _, builtin := range builtins {
+		e.Set(builtin.Name, builtin, true)
+	}
+	for _, trait := range BuiltinTraits {
+		e.Set(trait.TraitName, trait, true)
+	}
+}
+
+func builtinPrint(args []Value, source utils.String) (Value, error) {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.String()
+	}
+	fmt.Println(strings.Join(parts, " "))
+	return nil, nil
+}
+
+func expectArgs(name string, args []Value, count int, source utils.String) error {
+	if len(args) != count {
+		return utils.Error{Source: source, Message: name + " expects " + strconv.Itoa(count) + " arguments, got " + strconv.Itoa(len(args))}
+	}
+	return nil
+}
+
+func builtinLen(args []Value, source utils.String) (Value, error) {
+	if err := expectArgs("len", args, 1, source); err != nil {
+		return nil, err
+	}
+	switch arg := args[0].(type) {
+	case *ListValue:
+		return lengthValue(big.NewInt(arg.Len()), source)
+	case *RangeValue:
+		return lengthValue(arg.Count(), source)
+	case BaseValue[string]:
+		return lengthValue(big.NewInt(int64(utf8.RuneCountInString(arg.value))), source)
+	}
+	return nil, utils.Error{Source: source, Message: "len is not defined for " + args[0].Type().Name()}
+}
+
+// lengthValue returns a length as the i32 the length builtins produce
+func lengthValue(n *big.Int, source utils.String) (Value, error) {
+	if !n.IsInt64() || n.Int64() > math.MaxInt32 {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("length %s does not fit in i32", n)}
+	}
+	return NewI32Value(int32(n.Int64()), source), nil
+}
+
+// builtinByteLen returns the length of a string in bytes of UTF-8
+func builtinByteLen(args []Value, source utils.String) (Value, error) {
+	s, err := expectStr("byte_len", args, source)
+	if err != nil {
+		return nil, err
+	}
+	return lengthValue(big.NewInt(int64(len(s))), source)
+}
+
+// builtinGraphemeLen returns the number of user-perceived characters of a string, see GraphemeCount
+func builtinGraphemeLen(args []Value, source utils.String) (Value, error) {
+	s, err := expectStr("grapheme_len", args, source)
+	if err != nil {
+		return nil, err
+	}
+	return lengthValue(big.NewInt(int64(GraphemeCount(s))), source)
+}
+
+func expectStr(name string, args []Value, source utils.String) (string, error) {
+	if err := expectArgs(name, args, 1, source); err != nil {
+		return "", err
+	}
+	s, ok := args[0].(BaseValue[string])
+	if !ok {
+		return "", utils.Error{Source: source, Message: name + " expects a str, got " + args[0].Type().Name()}
+	}
+	return s.value, nil
+}
+
+func builtinPush(args []Value, source utils.String) (Value, error) {
+	if err := expectArgs("push", args, 2, source); err != nil {
+		return nil, err
+	}
+	list, ok := args[0].(*ListValue)
+	if !ok {
+		return nil, utils.Error{Source: source, Message: "push expects a list, got " + args[0].Type().Name()}
+	}
+	if err := list.Push(Copy(args[1]), source); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func builtinPop(args []Value, source utils.String) (Value, error) {
+	if err := expectArgs("pop", args, 1, source); err != nil {
+		return nil, err
+	}
+	list, ok := args[0].(*ListValue)
+	if !ok {
+		return nil, utils.Error{Source: source, Message: "pop expects a list, got " + args[0].Type().Name()}
+	}
+	return list.Pop(source)
+}
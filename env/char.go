@@ -0,0 +1,69 @@
+package env
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"com.loop.anonx3247/utils"
+)
+
+// Strings hold UTF-8 text and are indexed and iterated by char, a Unicode code point. Their length is
+// available in bytes, chars and graphemes, see builtins.go.
+
+const zeroWidthJoiner = '\u200d'
+
+// IteratorOf returns an iterator over the elements of v, the chars of a string
+func IteratorOf(v Value) (Iterator, bool) {
+	switch val := v.(type) {
+	case Iterable:
+		return val.Iterator(), true
+	case BaseValue[string]:
+		return &strIterator{rest: val.value, source: val.source}, true
+	}
+	return nil, false
+}
+
+type strIterator struct {
+	rest   string
+	source utils.String
+}
+
+func (it *strIterator) Next() (Value, bool) {
+	if it.rest == "" {
+		return nil, false
+	}
+	r, size := utf8.DecodeRuneInString(it.rest)
+	it.rest = it.rest[size:]
+	return NewCharValue(r, it.source), true
+}
+
+// GraphemeCount counts the user-perceived characters of s. It follows the main rules of extended
+// grapheme clusters: combining marks, variation selectors and emoji modifiers extend the previous
+// character, a zero width joiner joins two characters, regional indicators pair up into flags and
+// \r\n is a single character.
+func GraphemeCount(s string) int {
+	count, regionalIndicators := 0, 0
+	var previous rune
+	for i, r := range s {
+		extends := i > 0 && (previous == zeroWidthJoiner || isGraphemeExtender(r) || (previous == '\r' && r == '\n'))
+		if r >= 0x1f1e6 && r <= 0x1f1ff {
+			extends = extends || regionalIndicators%2 == 1
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+		if !extends {
+			count++
+		}
+		previous = r
+	}
+	return count
+}
+
+func isGraphemeExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zeroWidthJoiner ||
+		(r >= 0xfe00 && r <= 0xfe0f) || // variation selectors
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // skin tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) // emoji tags
+}
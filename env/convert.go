@@ -0,0 +1,249 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"com.loop.anonx3247/utils"
+)
+
+// ConversionMode decides what happens when a value does not fit in the type it is converted to
+type ConversionMode int
+
+const (
+	Checked    ConversionMode = iota // values that do not fit are an error, `x as u8`, `u8(x)`
+	Wrapping                         // integers keep their low bits, `u8.wrapping(x)`
+	Saturating                       // values are clamped to the bounds of the type, `u8.saturating(x)`
+)
+
+var conversionModeNames = map[ConversionMode]string{
+	Checked:    "checked",
+	Wrapping:   "wrapping",
+	Saturating: "saturating",
+}
+
+func (m ConversionMode) String() string {
+	return conversionModeNames[m]
+}
+
+// ConversionModeFromName resolves the mode named in `u8.wrapping(x)`
+func ConversionModeFromName(name string) (ConversionMode, bool) {
+	for mode, n := range conversionModeNames {
+		if n == name {
+			return mode, true
+		}
+	}
+	return -1, false
+}
+
+// CanConvert reports whether values of type from can be converted to t. Numbers convert to each
+// other, bools convert to numbers, strings are parsed and every value converts to a string. Chars
+// convert to and from integers through their code point, and from strings of a single char.
+func CanConvert(from Type, t BaseType) bool {
+	switch {
+	case t == Str || from.BaseType() == t:
+		return true
+	case t == Char:
+		return from.BaseType().IsInteger() || from.BaseType() == Str
+	case from.BaseType() == Char:
+		return t.IsInteger()
+	case from.BaseType() == Str:
+		return t <= Bool
+	case t.IsInteger() || t.IsFloat():
+		return from.BaseType() <= Bool
+	}
+	return false
+}
+
+// Convert converts v to the base type t, see CanConvert. Fractions are truncated when converting
+// floats to integers, mode decides what happens to values that do not fit in t.
+func Convert(v Value, t BaseType, mode ConversionMode, source utils.String) (Value, error) {
+	if v == nil || !CanConvert(v.Type(), t) {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot convert %s to %s", typeName(TypeOf(v)), t.Name())}
+	}
+	if t == Str {
+		if s, ok := v.(BaseValue[string]); ok {
+			return NewStrValue(s.value, source), nil
+		}
+		return NewStrValue(v.String(), source), nil
+	}
+	if t == Char {
+		return convertToChar(v, source)
+	}
+	if c, ok := v.(BaseValue[CodePoint]); ok {
+		return FitInteger(big.NewInt(int64(c.value)), t, mode, source)
+	}
+	if s, ok := v.(BaseValue[string]); ok {
+		return parseConverted(s.value, t, mode, source)
+	}
+	if b, ok := v.(BaseValue[bool]); ok {
+		if t == Bool {
+			return NewBoolValue(b.value, source), nil
+		}
+		n := int64(0)
+		if b.value {
+			n = 1
+		}
+		return FitInteger(big.NewInt(n), t, mode, source)
+	}
+	if i, ok := BigIntValue(v); ok {
+		if t.IsFloat() {
+			f, _ := new(big.Float).SetInt(i).Float64()
+			return convertFloat(f, t, mode, source)
+		}
+		return FitInteger(i, t, mode, source)
+	}
+	f, _ := FloatValue(v)
+	if t.IsFloat() {
+		return convertFloat(f, t, mode, source)
+	}
+	return convertFloatToInt(f, t, mode, source)
+}
+
+// FloatValue extracts the value of a float base value as a float64
+func FloatValue(v Value) (float64, bool) {
+	switch val := v.(type) {
+	case BaseValue[float32]:
+		return float64(val.value), true
+	case BaseValue[float64]:
+		return val.value, true
+	}
+	return 0, false
+}
+
+// BigIntValue extracts the value of any integer base value, including u64 values above the range of int64
+func BigIntValue(v Value) (*big.Int, bool) {
+	if u, ok := v.(BaseValue[uint64]); ok {
+		return new(big.Int).SetUint64(u.value), true
+	}
+	i, ok := IntValue(v)
+	if !ok {
+		return nil, false
+	}
+	return big.NewInt(i), true
+}
+
+// integerBounds returns the smallest and largest values of the integer type t
+func integerBounds(t BaseType) (*big.Int, *big.Int) {
+	bits := uint(integerBits[t])
+	if t >= U8 {
+		max := new(big.Int).Lsh(big.NewInt(1), bits)
+		return big.NewInt(0), max.Sub(max, big.NewInt(1))
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	min := new(big.Int).Neg(max)
+	return min, max.Sub(max, big.NewInt(1))
+}
+
+// FitInteger builds a value of the integer type t from i, mode decides what happens when i does not fit
+func FitInteger(i *big.Int, t BaseType, mode ConversionMode, source utils.String) (Value, error) {
+	min, max := integerBounds(t)
+	if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+		switch mode {
+		case Checked:
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("value %s does not fit in %s", i, t.Name())}
+		case Saturating:
+			if i.Sign() < 0 {
+				i = min
+			} else {
+				i = max
+			}
+		case Wrapping:
+			// the low bits of a two's complement representation, reinterpreted in t
+			modulus := new(big.Int).Lsh(big.NewInt(1), uint(integerBits[t]))
+			i = new(big.Int).Mod(i, modulus)
+			if i.Cmp(max) > 0 {
+				i.Sub(i, modulus)
+			}
+		}
+	}
+	if t == U64 {
+		return NewU64Value(i.Uint64(), source), nil
+	}
+	return NewIntValue(t, i.Int64(), source), nil
+}
+
+// convertToChar converts a string of a single char or a valid code point to a char, whatever the mode
+func convertToChar(v Value, source utils.String) (Value, error) {
+	if s, ok := v.(BaseValue[string]); ok {
+		if utf8.RuneCountInString(s.value) != 1 {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot convert %s to char, it holds %d chars", strconv.Quote(s.value), utf8.RuneCountInString(s.value))}
+		}
+		r, _ := utf8.DecodeRuneInString(s.value)
+		return NewCharValue(r, source), nil
+	}
+	if c, ok := v.(BaseValue[CodePoint]); ok {
+		return NewCharValue(rune(c.value), source), nil
+	}
+	i, _ := BigIntValue(v)
+	if !i.IsInt64() || i.Int64() < 0 || i.Int64() > utf8.MaxRune || !utf8.ValidRune(rune(i.Int64())) {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s is not a valid char code point", i)}
+	}
+	return NewCharValue(rune(i.Int64()), source), nil
+}
+
+func convertFloat(f float64, t BaseType, mode ConversionMode, source utils.String) (Value, error) {
+	if t == F64 {
+		return NewF64Value(f, source), nil
+	}
+	if math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		switch mode {
+		case Checked:
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("value %v does not fit in f32", f)}
+		case Saturating:
+			return NewF32Value(float32(math.Copysign(math.MaxFloat32, f)), source), nil
+		}
+	}
+	return NewF32Value(float32(f), source), nil
+}
+
+func convertFloatToInt(f float64, t BaseType, mode ConversionMode, source utils.String) (Value, error) {
+	if math.IsNaN(f) {
+		if mode == Checked {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot convert nan to %s", t.Name())}
+		}
+		return NewIntValue(t, 0, source), nil
+	}
+	if math.IsInf(f, 0) {
+		if mode != Saturating {
+			return nil, utils.Error{Source: source, Message: fmt.Sprintf("value %v does not fit in %s", f, t.Name())}
+		}
+		min, max := integerBounds(t)
+		if f < 0 {
+			return FitInteger(min, t, mode, source)
+		}
+		return FitInteger(max, t, mode, source)
+	}
+	i, _ := big.NewFloat(math.Trunc(f)).Int(nil)
+	return FitInteger(i, t, mode, source)
+}
+
+// parseConverted reads a number or a bool written in a string, as in `i32('42')`
+func parseConverted(s string, t BaseType, mode ConversionMode, source utils.String) (Value, error) {
+	invalid := utils.Error{Source: source, Message: fmt.Sprintf("cannot convert %s to %s", strconv.Quote(s), t.Name())}
+	if t == Bool {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, invalid
+		}
+		return NewBoolValue(b, source), nil
+	}
+	if i, ok := new(big.Int).SetString(s, 10); ok {
+		if t.IsFloat() {
+			f, _ := new(big.Float).SetInt(i).Float64()
+			return convertFloat(f, t, mode, source)
+		}
+		return FitInteger(i, t, mode, source)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	if t.IsFloat() {
+		return convertFloat(f, t, mode, source)
+	}
+	return convertFloatToInt(f, t, mode, source)
+}
@@ -0,0 +1,69 @@
+package env
+
+import (
+	"math/big"
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+func bigInt(s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid test integer " + s)
+	}
+	return i
+}
+
+func TestFitInteger(t *testing.T) {
+	tests := []struct {
+		value string
+		t     BaseType
+		mode  ConversionMode
+		want  string // the fitted value, or the error message when err is set
+		err   bool
+	}{
+		{"100", I8, Checked, "100", false},
+		{"-128", I8, Checked, "-128", false},
+		{"128", I8, Checked, "value 128 does not fit in i8", true},
+		{"128", I8, Wrapping, "-128", false},
+		{"-129", I8, Wrapping, "127", false},
+		{"128", I8, Saturating, "127", false},
+		{"-129", I8, Saturating, "-128", false},
+		{"300", U8, Checked, "value 300 does not fit in u8", true},
+		{"300", U8, Wrapping, "44", false},
+		{"300", U8, Saturating, "255", false},
+		{"-1", U8, Checked, "value -1 does not fit in u8", true},
+		{"-1", U8, Wrapping, "255", false},
+		{"-1", U8, Saturating, "0", false},
+		{"65536", U16, Wrapping, "0", false},
+		{"-32769", I16, Saturating, "-32768", false},
+		{"4294967296", U32, Wrapping, "0", false},
+		{"2147483648", I32, Checked, "value 2147483648 does not fit in i32", true},
+		{"9223372036854775807", I64, Checked, "9223372036854775807", false},
+		{"9223372036854775808", I64, Wrapping, "-9223372036854775808", false},
+		{"9223372036854775808", I64, Saturating, "9223372036854775807", false},
+		{"-9223372036854775809", I64, Saturating, "-9223372036854775808", false},
+		{"18446744073709551615", U64, Checked, "18446744073709551615", false},
+		{"18446744073709551616", U64, Checked, "value 18446744073709551616 does not fit in u64", true},
+		{"18446744073709551616", U64, Wrapping, "0", false},
+		{"18446744073709551617", U64, Saturating, "18446744073709551615", false},
+		{"-1", U64, Wrapping, "18446744073709551615", false},
+	}
+	for _, test := range tests {
+		v, err := FitInteger(bigInt(test.value), test.t, test.mode, utils.String{})
+		if test.err {
+			if err == nil || err.(utils.Error).Message != test.want {
+				t.Errorf("FitInteger(%s, %s, %s) = %v, %v, want error %q", test.value, test.t.Name(), test.mode, v, err, test.want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FitInteger(%s, %s, %s) failed: %v", test.value, test.t.Name(), test.mode, err)
+			continue
+		}
+		if v.String() != test.want || v.Type().BaseType() != test.t {
+			t.Errorf("FitInteger(%s, %s, %s) = %s of type %s, want %s", test.value, test.t.Name(), test.mode, v, v.Type().Name(), test.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package env
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+// Variant is one case of an enum: a unit variant has a name and no payload,
+// a tuple variant carries a payload and may be anonymous (empty name)
+type Variant struct {
+	Name    string
+	Payload []Type
+}
+
+func (v Variant) IsUnit() bool {
+	return v.Payload == nil
+}
+
+func (v Variant) String() string {
+	if v.IsUnit() {
+		return v.Name
+	}
+	if v.Name == "" {
+		return TupleType{Elems: v.Payload}.Name()
+	}
+	types := make([]string, len(v.Payload))
+	for i, t := range v.Payload {
+		types[i] = typeName(t)
+	}
+	return v.Name + "(" + strings.Join(types, ", ") + ")"
+}
+
+// EnumType is the type declared by `enum`, like StructType it is also a value:
+// `Color.Black` and `Color.Rgb(...)` build named variants, `Color(...)` anonymous ones
+type EnumType struct {
+	source   utils.String
+	TypeName string
+	Variants []Variant
+	Impls    []*Impl // the traits implemented by the enum
+}
+
+func NewEnumType(name string, variants []Variant, source utils.String) *EnumType {
+	return &EnumType{source: source, TypeName: name, Variants: variants}
+}
+
+func (e *EnumType) BaseType() BaseType {
+	return Enum
+}
+
+func (e *EnumType) Name() string {
+	return e.TypeName
+}
+
+// VariantIndex returns the position of the named variant, or -1
+func (e *EnumType) VariantIndex(name string) int {
+	for i, variant := range e.Variants {
+		if variant.Name != "" && variant.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AnonymousVariant returns the position of the anonymous variant whose payload
+// has the given types, or -1
+func (e *EnumType) AnonymousVariant(types []Type) int {
+	for i, variant := range e.Variants {
+		if variant.Name == "" && SameType(TupleType{Elems: types}, TupleType{Elems: variant.Payload}) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *EnumType) Type() Type {
+	return e
+}
+
+func (e *EnumType) Source() utils.String {
+	return e.source
+}
+
+func (e *EnumType) IsBase() bool {
+	return false
+}
+
+func (e *EnumType) String() string {
+	return "<enum " + e.TypeName + ">"
+}
+
+// EnumValue is one variant of an enum along with its payload
+type EnumValue struct {
+	source   utils.String
+	EnumType *EnumType
+	Variant  int
+	Payload  []Value
+}
+
+func NewEnumValue(enumType *EnumType, variant int, payload []Value, source utils.String) *EnumValue {
+	return &EnumValue{source: source, EnumType: enumType, Variant: variant, Payload: payload}
+}
+
+func (e *EnumValue) Type() Type {
+	return e.EnumType
+}
+
+func (e *EnumValue) Source() utils.String {
+	return e.source
+}
+
+func (e *EnumValue) IsBase() bool {
+	return false
+}
+
+func (e *EnumValue) String() string {
+	variant := e.EnumType.Variants[e.Variant]
+	if variant.IsUnit() {
+		return e.EnumType.TypeName + "." + variant.Name
+	}
+	items := make([]string, len(e.Payload))
+	for i, item := range e.Payload {
+		items[i] = item.String()
+	}
+	name := e.EnumType.TypeName
+	if variant.Name != "" {
+		name += "." + variant.Name
+	}
+	return name + "(" + strings.Join(items, ", ") + ")"
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"com.loop.anonx3247/utils"
+)
+
+// escapes are the characters standing for themselves or for a control character after a backslash
+var escapes = map[byte]byte{
+	'n':  '\n',
+	't':  '\t',
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'{':  '{',
+	'}':  '}',
+}
+
+// decodeEscapes decodes the escape sequences of the text of a string literal, `\n`, `\t`, `\\`, `\"`,
+// `\'`, the braces `\{` and `\}`, and code points `\u{1F600}`. Errors point at the offending character.
+func decodeEscapes(text utils.String) (string, error) {
+	s := text.String()
+	errorAt := func(i, length int, message string) error {
+		return utils.Error{Source: utils.String{Ptr: text.Ptr, Start: text.Start + i, Length: length}, Message: message}
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", errorAt(i-1, 1, "unterminated escape sequence")
+		}
+		if c, ok := escapes[s[i]]; ok {
+			b.WriteByte(c)
+			continue
+		}
+		if s[i] != 'u' {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			return "", errorAt(i, size, fmt.Sprintf("invalid escape sequence \\%c", r))
+		}
+		r, end, err := decodeCodePoint(s, i+1, errorAt)
+		if err != nil {
+			return "", err
+		}
+		b.WriteRune(r)
+		i = end
+	}
+	return b.String(), nil
+}
+
+// decodeCodePoint decodes the `{1F600}` of a unicode escape starting at start, end is the position of
+// its closing brace
+func decodeCodePoint(s string, start int, errorAt func(i, length int, message string) error) (r rune, end int, err error) {
+	if start == len(s) || s[start] != '{' {
+		return 0, 0, errorAt(start, 1, "expected { after \\u")
+	}
+	end = start + 1
+	for end < len(s) && s[end] != '}' {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(s[end])) {
+			return 0, 0, errorAt(end, 1, "invalid hexadecimal digit in unicode escape")
+		}
+		if end-start > 6 {
+			return 0, 0, errorAt(end, 1, "unicode escape has more than 6 digits")
+		}
+		end++
+	}
+	switch {
+	case end == len(s):
+		return 0, 0, errorAt(start, 1, "unterminated unicode escape, expected }")
+	case end == start+1:
+		return 0, 0, errorAt(end, 1, "empty unicode escape")
+	}
+	digits := s[start+1 : end]
+	code, _ := strconv.ParseUint(digits, 16, 32)
+	if code > utf8.MaxRune || (code >= 0xD800 && code <= 0xDFFF) {
+		return 0, 0, errorAt(start+1, len(digits), fmt.Sprintf("%s is not a valid unicode code point", digits))
+	}
+	return rune(code), end, nil
+}
@@ -0,0 +1,95 @@
+package env
+
+import (
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+func TestDecodeEscapes(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{``, ""},
+		{`plain text`, "plain text"},
+		{`a\nb\tc`, "a\nb\tc"},
+		{`\\ \" \'`, `\ " '`},
+		{`\{name\}`, "{name}"},
+		{`\u{41}\u{e9}`, "Aé"},
+		{`\u{1F600}!`, "😀!"},
+		{`\u{10FFFF}`, "\U0010FFFF"},
+		{`é\n`, "é\n"},
+	}
+	for _, test := range tests {
+		got, err := decodeEscapes(utils.StringFrom(test.text, 0, len(test.text)))
+		if err != nil {
+			t.Errorf("decodeEscapes(%q) failed: %v", test.text, err)
+		} else if got != test.want {
+			t.Errorf("decodeEscapes(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestDecodeEscapesErrors(t *testing.T) {
+	tests := []struct {
+		text    string
+		message string
+		at      string // the text the error points at
+	}{
+		{`abc\`, "unterminated escape sequence", `\`},
+		{`\q`, `invalid escape sequence \q`, "q"},
+		{`\é`, `invalid escape sequence \é`, "é"},
+		{`\u41`, `expected { after \u`, "4"},
+		{`\u`, `expected { after \u`, ""},
+		{`\u{}`, "empty unicode escape", "}"},
+		{`\u{12`, "unterminated unicode escape, expected }", "{"},
+		{`\u{zz}`, "invalid hexadecimal digit in unicode escape", "z"},
+		{`\u{1234567}`, "unicode escape has more than 6 digits", "7"},
+		{`\u{D800}`, "D800 is not a valid unicode code point", "D800"},
+		{`\u{110000}`, "110000 is not a valid unicode code point", "110000"},
+	}
+	for _, test := range tests {
+		_, err := decodeEscapes(utils.StringFrom(test.text, 0, len(test.text)))
+		e, ok := err.(utils.Error)
+		if !ok {
+			t.Errorf("decodeEscapes(%q) = %v, want error %q", test.text, err, test.message)
+			continue
+		}
+		if e.Message != test.message {
+			t.Errorf("decodeEscapes(%q) reports %q, want %q", test.text, e.Message, test.message)
+		}
+		if at := sourceText(e.Source); at != test.at {
+			t.Errorf("decodeEscapes(%q) points at %q, want %q", test.text, at, test.at)
+		}
+	}
+}
+
+func TestDecodeCodePoint(t *testing.T) {
+	tests := []struct {
+		s     string
+		start int
+		r     rune
+		end   int
+	}{
+		{`{41}`, 0, 'A', 3},
+		{`\u{1F600} rest`, 2, '😀', 8},
+		{`{000041}`, 0, 'A', 7},
+	}
+	errorAt := func(i, length int, message string) error {
+		return utils.Error{Message: message}
+	}
+	for _, test := range tests {
+		r, end, err := decodeCodePoint(test.s, test.start, errorAt)
+		if err != nil || r != test.r || end != test.end {
+			t.Errorf("decodeCodePoint(%q, %d) = %q, %d, %v, want %q, %d", test.s, test.start, r, end, err, test.r, test.end)
+		}
+	}
+}
+
+// sourceText returns the text s points at, clamped to its source since errors at the end of a text
+// point just past it
+func sourceText(s utils.String) string {
+	end := min(s.Start+s.Length, len(*s.Ptr))
+	return (*s.Ptr)[min(s.Start, end):end]
+}
@@ -0,0 +1,160 @@
+package env
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+// Body is the executable part of a function, implemented by ast.Expr
+type Body interface {
+	Source() utils.String
+	Eval(env *Env) (Value, error)
+}
+
+type Param struct {
+	Name    string
+	Type    Type
+	Mutable bool // declared with `mut`, so the body may assign to it
+	Named   bool // declared in a `{...}` group, so it is passed as `name: value`
+	Default Body // evaluated in the environment of the call when the argument is omitted, or nil
+}
+
+// Optional reports whether the argument of the parameter may be omitted, a named parameter of
+// an optional type defaults to none
+func (p Param) Optional() bool {
+	_, isOptional := p.Type.(OptionalType)
+	return p.Default != nil || (p.Named && isOptional)
+}
+
+type FunctionType struct {
+	TypeParams []TypeParam  // set for generic functions
+	Params     []Type       // positional parameters
+	Defaults   int          // number of trailing positional parameters with a default value
+	Named      []NamedParam // parameters passed as `name: value`
+	Return     Type         // nil when the return type is inferred
+}
+
+// NamedParam is a parameter of a function type passed by name
+type NamedParam struct {
+	Name     string
+	Type     Type
+	Optional bool // may be omitted, see Param.Optional
+}
+
+// NewFunctionType returns the type of a function with the given parameters
+func NewFunctionType(typeParams []TypeParam, params []Param, ret Type) FunctionType {
+	t := FunctionType{TypeParams: typeParams, Return: ret}
+	for _, param := range params {
+		if param.Named {
+			t.Named = append(t.Named, NamedParam{Name: param.Name, Type: param.Type, Optional: param.Optional()})
+			continue
+		}
+		t.Params = append(t.Params, param.Type)
+		if param.Default != nil {
+			t.Defaults++
+		}
+	}
+	return t
+}
+
+func (f FunctionType) BaseType() BaseType {
+	return Function
+}
+
+func (f FunctionType) Name() string {
+	params := make([]string, len(f.Params))
+	for i, param := range f.Params {
+		params[i] = typeName(param)
+		if i >= len(f.Params)-f.Defaults {
+			params[i] += " = ..."
+		}
+	}
+	if len(f.Named) > 0 {
+		named := make([]string, len(f.Named))
+		for i, param := range f.Named {
+			named[i] = param.Name + ": " + typeName(param.Type)
+			if param.Optional {
+				named[i] += " = ..."
+			}
+		}
+		params = append(params, "{"+strings.Join(named, ", ")+"}")
+	}
+	name := "fn"
+	if len(f.TypeParams) > 0 {
+		typeParams := make([]string, len(f.TypeParams))
+		for i, param := range f.TypeParams {
+			typeParams[i] = param.String()
+		}
+		name += "<" + strings.Join(typeParams, ", ") + ">"
+	}
+	if f.Return == nil {
+		return name + "(" + strings.Join(params, ", ") + ")"
+	}
+	return name + "(" + strings.Join(params, ", ") + "): " + f.Return.Name()
+}
+
+// FunctionValue is a function declared in loop source
+type FunctionValue struct {
+	source     utils.String
+	Name       string
+	TypeParams []TypeParam
+	Params     []Param
+	Return     Type
+	Body       Body
+	Env        *Env // scope the function was declared in, calls run in a child of it
+}
+
+func NewFunctionValue(name string, typeParams []TypeParam, params []Param, ret Type, body Body, declEnv *Env, source utils.String) *FunctionValue {
+	return &FunctionValue{source: source, Name: name, TypeParams: typeParams, Params: params, Return: ret, Body: body, Env: declEnv}
+}
+
+func (f *FunctionValue) Type() Type {
+	return NewFunctionType(f.TypeParams, f.Params, f.Return)
+}
+
+func (f *FunctionValue) Source() utils.String {
+	return f.source
+}
+
+func (f *FunctionValue) IsBase() bool {
+	return false
+}
+
+func (f *FunctionValue) String() string {
+	if f.Name == "" {
+		return "<fn>"
+	}
+	return "<fn " + f.Name + ">"
+}
+
+// BuiltinFunction is a function implemented by the interpreter itself
+type BuiltinFunction struct {
+	Name    string
+	Params  []Type // types given to literal arguments, nil when any value is accepted
+	Mutates bool   // changes its first argument in place, which must not be reached through an immutable variable
+	Call    func(args []Value, source utils.String) (Value, error)
+}
+
+func (b *BuiltinFunction) Type() Type {
+	return FunctionType{}
+}
+
+func (b *BuiltinFunction) Source() utils.String {
+	return utils.String{}
+}
+
+func (b *BuiltinFunction) IsBase() bool {
+	return false
+}
+
+func (b *BuiltinFunction) String() string {
+	return "<builtin " + b.Name + ">"
+}
+
+func typeName(t Type) string {
+	if t == nil {
+		return "_"
+	}
+	return t.Name()
+}
@@ -0,0 +1,144 @@
+package env
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+// TypeParam is a type parameter of a generic function, `T implements Addition` in
+// `fn sum<T implements Addition>(items: List<T>)`. Types mention it as NamedType{"T"}.
+type TypeParam struct {
+	Name   string
+	Bounds []string // traits the type argument has to implement
+}
+
+func (p TypeParam) String() string {
+	if len(p.Bounds) == 0 {
+		return p.Name
+	}
+	return p.Name + " implements " + strings.Join(p.Bounds, " + ")
+}
+
+// TypeBindings maps the type parameters of a generic function to their type arguments, which are
+// inferred from the arguments of a call and stay nil until then
+type TypeBindings map[string]Type
+
+func NewTypeBindings(params []TypeParam) TypeBindings {
+	b := TypeBindings{}
+	for _, param := range params {
+		b[param.Name] = nil
+	}
+	return b
+}
+
+// Infer binds the type parameters mentioned by param to the matching parts of arg, as `List<T>`
+// and `List<i32>` bind T to i32. A parameter keeps the first type it is bound to.
+func (b TypeBindings) Infer(param, arg Type) {
+	if arg == nil {
+		return
+	}
+	switch p := param.(type) {
+	case NamedType:
+		if bound, ok := b[p.TypeName]; ok && bound == nil && p.Module == "" {
+			b[p.TypeName] = arg
+		}
+	case ListType:
+		if a, ok := arg.(ListType); ok {
+			b.Infer(p.Elem, a.Elem)
+		}
+	case OptionalType:
+		if a, ok := arg.(OptionalType); ok {
+			b.Infer(p.Elem, a.Elem)
+		} else {
+			b.Infer(p.Elem, arg)
+		}
+	case TupleType:
+		if a, ok := arg.(TupleType); ok && len(a.Elems) == len(p.Elems) {
+			for i := range p.Elems {
+				b.Infer(p.Elems[i], a.Elems[i])
+			}
+		}
+	case FunctionType:
+		if a, ok := arg.(FunctionType); ok && len(a.Params) == len(p.Params) {
+			for i := range p.Params {
+				b.Infer(p.Params[i], a.Params[i])
+			}
+			b.Infer(p.Return, a.Return)
+		}
+	}
+}
+
+// Substitute replaces the type parameters in t by their type arguments, nil while not inferred
+func (b TypeBindings) Substitute(t Type) Type {
+	return SubstituteNamed(t, func(name string) (Type, bool) {
+		arg, ok := b[name]
+		return arg, ok
+	})
+}
+
+// SubstituteNamed replaces the named types of t for which lookup returns a type
+func SubstituteNamed(t Type, lookup func(name string) (Type, bool)) Type {
+	switch t := t.(type) {
+	case NamedType:
+		if t.Module != "" {
+			return t
+		}
+		if arg, ok := lookup(t.TypeName); ok {
+			return arg
+		}
+	case ListType:
+		return ListType{Elem: SubstituteNamed(t.Elem, lookup)}
+	case OptionalType:
+		if t.Elem != nil {
+			return OptionalType{Elem: SubstituteNamed(t.Elem, lookup)}
+		}
+	case TupleType:
+		elems := make([]Type, len(t.Elems))
+		for i, elem := range t.Elems {
+			elems[i] = SubstituteNamed(elem, lookup)
+		}
+		return TupleType{Elems: elems}
+	case FunctionType:
+		params := make([]Type, len(t.Params))
+		for i, param := range t.Params {
+			params[i] = SubstituteNamed(param, lookup)
+		}
+		named := make([]NamedParam, len(t.Named))
+		for i, param := range t.Named {
+			param.Type = SubstituteNamed(param.Type, lookup)
+			named[i] = param
+		}
+		return FunctionType{TypeParams: t.TypeParams, Params: params, Defaults: t.Defaults, Named: named, Return: SubstituteNamed(t.Return, lookup)}
+	}
+	return t
+}
+
+// TypeArgValue is the value of a type parameter during a call of a generic function, `T` in
+// `T.zero` reads the associated constant of the type argument through the traits bounding T
+type TypeArgValue struct {
+	source utils.String
+	Param  TypeParam
+	Arg    Type
+	Traits []*TraitType // the bounds of the parameter
+}
+
+func NewTypeArgValue(param TypeParam, arg Type, traits []*TraitType, source utils.String) TypeArgValue {
+	return TypeArgValue{source: source, Param: param, Arg: arg, Traits: traits}
+}
+
+func (t TypeArgValue) Type() Type {
+	return t.Arg
+}
+
+func (t TypeArgValue) Source() utils.String {
+	return t.source
+}
+
+func (t TypeArgValue) IsBase() bool {
+	return false
+}
+
+func (t TypeArgValue) String() string {
+	return t.Arg.Name()
+}
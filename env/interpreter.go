@@ -1,28 +1,85 @@
 package env
 
 type Env struct {
-	vars map[string]Var
+	parent   *Env
+	vars     map[string]Var
+	overflow OverflowPolicy // only set on the root scope, see Overflow
+	importer Importer       // only set on the root scope, see Importer
 }
 
 type Var struct {
-	Const bool
-	Name  string
-	Value Value
+	Const  bool
+	Name   string
+	Value  Value
+	Type   Type // the declared type, values assigned later must have it
+	Public bool // declared with `pub`, so other modules can import it
 }
 
 func NewEnv() *Env {
-	return &Env{vars: make(map[string]Var)}
+	e := &Env{vars: make(map[string]Var)}
+	defineBuiltins(e)
+	return e
 }
 
+// NewChild creates a nested scope, lookups that fail in the child continue in e
+func (e *Env) NewChild() *Env {
+	return &Env{parent: e, vars: make(map[string]Var)}
+}
+
+func (e *Env) Parent() *Env {
+	return e.parent
+}
+
+// Set declares a variable in this scope, shadowing any variable of the same name in outer scopes.
+// The variable has the type of value.
 func (e *Env) Set(name string, value Value, isConst bool) {
+	e.Declare(name, value, nil, isConst)
+}
+
+// Declare declares a variable of type t in this scope, as in `x : i32? = none`, a nil t stands
+// for the type of value
+func (e *Env) Declare(name string, value Value, t Type, isConst bool) {
+	if t == nil {
+		t = TypeOf(value)
+	}
 	e.vars[name] = Var{
 		Const: isConst,
 		Name:  name,
 		Value: value,
+		Type:  t,
 	}
 }
 
+// Assign overwrites an existing variable in the scope that declared it,
+// it returns false if no scope declares name
+func (e *Env) Assign(name string, value Value) bool {
+	for scope := e; scope != nil; scope = scope.parent {
+		if v, ok := scope.vars[name]; ok {
+			v.Value = value
+			scope.vars[name] = v
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup finds the innermost variable declared as name
+func (e *Env) Lookup(name string) (Var, bool) {
+	for scope := e; scope != nil; scope = scope.parent {
+		if v, ok := scope.vars[name]; ok {
+			return v, true
+		}
+	}
+	return Var{}, false
+}
+
 func (e *Env) Get(name string) (Value, bool) {
-	value, ok := e.vars[name]
+	value, ok := e.Lookup(name)
 	return value.Value, ok
 }
+
+// Declared reports whether name is declared in this scope, ignoring outer scopes
+func (e *Env) Declared(name string) bool {
+	_, ok := e.vars[name]
+	return ok
+}
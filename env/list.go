@@ -0,0 +1,103 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+type ListType struct {
+	Elem Type // nil until the element type is known, e.g. for `[]`
+}
+
+func (l ListType) BaseType() BaseType {
+	return List
+}
+
+func (l ListType) Name() string {
+	return "List<" + typeName(l.Elem) + ">"
+}
+
+// ListValue is a growable list whose elements all share the same type,
+// lists are shared by reference so pushing through one binding is seen by all
+type ListValue struct {
+	source utils.String
+	Elem   Type
+	Items  []Value
+}
+
+func NewListValue(elem Type, items []Value, source utils.String) *ListValue {
+	return &ListValue{source: source, Elem: elem, Items: items}
+}
+
+func (l *ListValue) Type() Type {
+	return ListType{Elem: l.Elem}
+}
+
+func (l *ListValue) Source() utils.String {
+	return l.source
+}
+
+func (l *ListValue) IsBase() bool {
+	return false
+}
+
+func (l *ListValue) String() string {
+	items := make([]string, len(l.Items))
+	for i, item := range l.Items {
+		items[i] = item.String()
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func (l *ListValue) Len() int64 {
+	return int64(len(l.Items))
+}
+
+// Push appends value, fixing the element type of a list that was created empty
+func (l *ListValue) Push(value Value, source utils.String) error {
+	if l.Elem == nil {
+		l.Elem = TypeOf(value)
+	} else if !SameType(value.Type(), l.Elem) {
+		return utils.Error{Source: source, Message: fmt.Sprintf("cannot push %s to %s", value.Type().Name(), l.Type().Name())}
+	}
+	l.Items = append(l.Items, value)
+	return nil
+}
+
+func (l *ListValue) Pop(source utils.String) (Value, error) {
+	if len(l.Items) == 0 {
+		return nil, utils.Error{Source: source, Message: "pop from empty list"}
+	}
+	last := l.Items[len(l.Items)-1]
+	l.Items = l.Items[:len(l.Items)-1]
+	return last, nil
+}
+
+// Slice copies the elements with indices in from..to (to excluded), every by-th one
+func (l *ListValue) Slice(from, to, by int64, source utils.String) *ListValue {
+	items := []Value{}
+	for i := from; i < to; i += by {
+		items = append(items, l.Items[i])
+	}
+	return NewListValue(l.Elem, items, source)
+}
+
+func (l *ListValue) Iterator() Iterator {
+	// iterate over a snapshot so that pushing inside a loop does not extend it
+	return &listIterator{items: l.Items}
+}
+
+type listIterator struct {
+	items []Value
+	index int
+}
+
+func (it *listIterator) Next() (Value, bool) {
+	if it.index >= len(it.items) {
+		return nil, false
+	}
+	it.index++
+	return it.items[it.index-1], true
+}
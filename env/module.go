@@ -0,0 +1,89 @@
+package env
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/utils"
+)
+
+// Importer loads the modules imported by the file being evaluated, see package loader
+type Importer interface {
+	// Import returns the module at path, `geometry.shapes` is geometry/shapes.lp. A local import,
+	// `use`, is only searched for next to the importing file.
+	Import(path []string, local bool, source utils.String) (*ModuleValue, error)
+}
+
+// Importer returns the importer of the program e belongs to, nil when it cannot import modules
+func (e *Env) Importer() Importer {
+	return e.root().importer
+}
+
+// SetImporter sets the importer of the program e belongs to
+func (e *Env) SetImporter(importer Importer) {
+	e.root().importer = importer
+}
+
+// Export makes the variable name of this scope public
+func (e *Env) Export(name string) {
+	if v, ok := e.vars[name]; ok {
+		v.Public = true
+		e.vars[name] = v
+	}
+}
+
+// ModuleType is the type of a module, it knows the types of its public declarations as found by
+// the checker and the names of its private ones
+type ModuleType struct {
+	ModuleName string
+	Members    map[string]Type
+	Private    map[string]bool
+}
+
+func (m ModuleType) BaseType() BaseType {
+	return Module
+}
+
+func (m ModuleType) Name() string {
+	return "mod " + m.ModuleName
+}
+
+// ModuleValue is a loaded module, `math` in `math.sqrt(2.0)` reads its public declarations
+type ModuleValue struct {
+	source     utils.String
+	ModuleName string
+	Path       string // file the module was loaded from
+	Env        *Env   // top level scope of the module
+	moduleType ModuleType
+}
+
+func NewModuleValue(name, path string, moduleEnv *Env, t ModuleType, source utils.String) *ModuleValue {
+	return &ModuleValue{source: source, ModuleName: name, Path: path, Env: moduleEnv, moduleType: t}
+}
+
+func (m *ModuleValue) Type() Type {
+	return m.moduleType
+}
+
+func (m *ModuleValue) Source() utils.String {
+	return m.source
+}
+
+func (m *ModuleValue) IsBase() bool {
+	return false
+}
+
+func (m *ModuleValue) String() string {
+	return "<mod " + m.ModuleName + ">"
+}
+
+// Member returns the public declaration name of the module
+func (m *ModuleValue) Member(name string, source utils.String) (Value, error) {
+	v, ok := m.Env.vars[name]
+	if !ok {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("module %s has no %s", m.ModuleName, name)}
+	}
+	if !v.Public {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s is private to module %s, declare it with pub", name, m.ModuleName)}
+	}
+	return v.Value, nil
+}
@@ -0,0 +1,59 @@
+package env
+
+import "com.loop.anonx3247/utils"
+
+// OptionalType is `T?`, a value of type T or none. Values of an optional type are stored as they
+// are, a T or a NoneValue, the optional type is that of the place holding them.
+type OptionalType struct {
+	Elem Type // nil for the type of none itself
+}
+
+func (o OptionalType) BaseType() BaseType {
+	return Optional
+}
+
+func (o OptionalType) Name() string {
+	if o.Elem == nil {
+		return "none"
+	}
+	return o.Elem.Name() + "?"
+}
+
+// NoneValue is `none`, the absence of a value in an optional
+type NoneValue struct {
+	source utils.String
+}
+
+func NewNoneValue(source utils.String) NoneValue {
+	return NoneValue{source: source}
+}
+
+func (n NoneValue) Type() Type {
+	return OptionalType{}
+}
+
+func (n NoneValue) Source() utils.String {
+	return n.source
+}
+
+func (n NoneValue) IsBase() bool {
+	return false
+}
+
+func (n NoneValue) String() string {
+	return "none"
+}
+
+// Unwrap returns T for a T?, and t itself for any other type
+func Unwrap(t Type) Type {
+	if o, ok := t.(OptionalType); ok {
+		return o.Elem
+	}
+	return t
+}
+
+// IsNone reports whether v is none
+func IsNone(v Value) bool {
+	_, ok := v.(NoneValue)
+	return ok
+}
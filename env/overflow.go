@@ -0,0 +1,58 @@
+package env
+
+// OverflowPolicy decides what integer arithmetic does with a result that does not fit in its type
+type OverflowPolicy int
+
+const (
+	Trap     OverflowPolicy = iota // the operation is an error
+	Wrap                           // the result keeps its low bits, as in two's complement hardware
+	Saturate                       // the result is clamped to the bounds of the type
+)
+
+var overflowPolicyNames = map[OverflowPolicy]string{
+	Trap:     "trap",
+	Wrap:     "wrap",
+	Saturate: "saturate",
+}
+
+func (p OverflowPolicy) String() string {
+	return overflowPolicyNames[p]
+}
+
+// OverflowPolicyFromName resolves `trap`, `wrap` or `saturate`
+func OverflowPolicyFromName(name string) (OverflowPolicy, bool) {
+	for policy, n := range overflowPolicyNames {
+		if n == name {
+			return policy, true
+		}
+	}
+	return -1, false
+}
+
+// Mode is the conversion mode that fits results in their type the way the policy asks
+func (p OverflowPolicy) Mode() ConversionMode {
+	switch p {
+	case Wrap:
+		return Wrapping
+	case Saturate:
+		return Saturating
+	}
+	return Checked
+}
+
+// Overflow returns the overflow policy of the program e belongs to
+func (e *Env) Overflow() OverflowPolicy {
+	return e.root().overflow
+}
+
+// SetOverflow sets the overflow policy of the program e belongs to, Trap by default
+func (e *Env) SetOverflow(policy OverflowPolicy) {
+	e.root().overflow = policy
+}
+
+func (e *Env) root() *Env {
+	for e.parent != nil {
+		e = e.parent
+	}
+	return e
+}
@@ -0,0 +1,46 @@
+package env
+
+import "testing"
+
+func TestOverflowPolicyFromName(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		mode   ConversionMode
+		ok     bool
+	}{
+		{"trap", Trap, Checked, true},
+		{"wrap", Wrap, Wrapping, true},
+		{"saturate", Saturate, Saturating, true},
+		{"wrapping", -1, Checked, false},
+		{"", -1, Checked, false},
+	}
+	for _, test := range tests {
+		policy, ok := OverflowPolicyFromName(test.name)
+		if policy != test.policy || ok != test.ok {
+			t.Errorf("OverflowPolicyFromName(%q) = %v, %v, want %v, %v", test.name, policy, ok, test.policy, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if policy.String() != test.name {
+			t.Errorf("%q resolves to a policy named %q", test.name, policy)
+		}
+		if policy.Mode() != test.mode {
+			t.Errorf("the %s policy fits results in %s mode, want %s", policy, policy.Mode(), test.mode)
+		}
+	}
+}
+
+func TestOverflowPolicyIsProgramWide(t *testing.T) {
+	e := NewEnv()
+	if e.Overflow() != Trap {
+		t.Errorf("a new program has overflow policy %s, want trap", e.Overflow())
+	}
+	child := e.NewChild().NewChild()
+	child.SetOverflow(Saturate)
+	if e.Overflow() != Saturate || child.Overflow() != Saturate {
+		t.Errorf("setting the policy from a nested scope gives %s at the top level and %s in the scope, want saturate", e.Overflow(), child.Overflow())
+	}
+}
@@ -0,0 +1,174 @@
+package env
+
+import (
+	"fmt"
+	"math/big"
+
+	"com.loop.anonx3247/utils"
+)
+
+type RangeType struct {
+	Elem BaseType
+}
+
+func (r RangeType) BaseType() BaseType {
+	return Range
+}
+
+func (r RangeType) Name() string {
+	return "Range<" + r.Elem.Name() + ">"
+}
+
+// RangeValue is an arithmetic progression of integers, `start..end` excludes end
+// while `start..=end` includes it. The step may be negative to count downwards.
+type RangeValue struct {
+	source    utils.String
+	Start     int64
+	End       int64
+	Step      int64
+	Inclusive bool
+	Elem      BaseType
+	stepped   bool
+}
+
+func NewRangeValue(elem BaseType, start, end int64, inclusive bool, source utils.String) *RangeValue {
+	return &RangeValue{source: source, Start: start, End: end, Step: 1, Inclusive: inclusive, Elem: elem}
+}
+
+// WithStep returns a copy of r counting in increments of step
+func (r *RangeValue) WithStep(step int64, source utils.String) (*RangeValue, error) {
+	if step == 0 {
+		return nil, utils.Error{Source: source, Message: "range step cannot be zero"}
+	}
+	if r.stepped {
+		return nil, utils.Error{Source: source, Message: "range already has a step"}
+	}
+	stepped := *r
+	stepped.source = source
+	stepped.Step = step
+	stepped.stepped = true
+	return &stepped, nil
+}
+
+func (r *RangeValue) Type() Type {
+	return RangeType{Elem: r.Elem}
+}
+
+func (r *RangeValue) Source() utils.String {
+	return r.source
+}
+
+func (r *RangeValue) IsBase() bool {
+	return false
+}
+
+func (r *RangeValue) String() string {
+	op := ".."
+	if r.Inclusive {
+		op = "..="
+	}
+	if r.stepped {
+		return fmt.Sprintf("%s%s%s..%d", r.bound(r.Start), op, r.bound(r.End), r.Step)
+	}
+	return fmt.Sprintf("%s%s%s", r.bound(r.Start), op, r.bound(r.End))
+}
+
+// bound returns the value of a bound or an element of the range, u64 values above the largest i64
+// are stored with the same bits, see IntValue
+func (r *RangeValue) bound(n int64) *big.Int {
+	if r.Elem == U64 {
+		return new(big.Int).SetUint64(uint64(n))
+	}
+	return big.NewInt(n)
+}
+
+// Count is the number of elements the range produces, computed exactly since the span of a 64 bit
+// range may not fit in 64 bits
+func (r *RangeValue) Count() *big.Int {
+	distance := new(big.Int).Sub(r.bound(r.End), r.bound(r.Start))
+	step := big.NewInt(r.Step)
+	if r.Step < 0 {
+		distance.Neg(distance)
+		step.Neg(step)
+	}
+	if r.Inclusive {
+		distance.Add(distance, big.NewInt(1))
+	}
+	if distance.Sign() <= 0 {
+		return distance.SetInt64(0)
+	}
+	// rounded up, 0..5..2 produces 0, 2 and 4
+	distance.Add(distance, step).Sub(distance, big.NewInt(1))
+	return distance.Quo(distance, step)
+}
+
+// Len is the number of elements the range produces, ok is false when it does not fit in an int64
+func (r *RangeValue) Len() (n int64, ok bool) {
+	count := r.Count()
+	return count.Int64(), count.IsInt64()
+}
+
+// At returns the i-th element, callers are expected to check bounds with Len. The result is
+// computed with wrapping arithmetic, which is exact for the elements of u64 and i64 ranges too.
+func (r *RangeValue) At(i int64) int64 {
+	return r.Start + i*r.Step
+}
+
+// Contains reports whether the range produces n
+func (r *RangeValue) Contains(n int64) bool {
+	offset := new(big.Int).Sub(r.bound(n), r.bound(r.Start))
+	index, rem := offset.QuoRem(offset, big.NewInt(r.Step), new(big.Int))
+	return rem.Sign() == 0 && index.Sign() >= 0 && index.Cmp(r.Count()) < 0
+}
+
+// Slice returns the elements with indices in from..to (to excluded), every by-th one. The slice
+// ends at its last element when the element past it does not fit in 64 bits.
+func (r *RangeValue) Slice(from, to, by int64, source utils.String) *RangeValue {
+	slice := &RangeValue{
+		source:  source,
+		Start:   r.At(from),
+		End:     r.At(to),
+		Step:    r.Step * by,
+		Elem:    r.Elem,
+		stepped: r.stepped || by != 1,
+	}
+	if to > from {
+		last := r.At(to - 1)
+		if r.bound(slice.End).Cmp(new(big.Int).Add(r.bound(last), big.NewInt(r.Step))) != 0 {
+			slice.End = last
+			slice.Inclusive = true
+		}
+	}
+	return slice
+}
+
+func (r *RangeValue) Iterator() Iterator {
+	count := r.Count()
+	if count.Sign() == 0 {
+		return &rangeIterator{done: true}
+	}
+	// a range of 64 bit integers may have 2^64 elements, one more than a uint64 counts
+	remaining := count.Sub(count, big.NewInt(1)).Uint64()
+	return &rangeIterator{rangeValue: r, next: r.Start, remaining: remaining}
+}
+
+type rangeIterator struct {
+	rangeValue *RangeValue
+	next       int64
+	remaining  uint64 // elements left after next
+	done       bool
+}
+
+func (it *rangeIterator) Next() (Value, bool) {
+	if it.done {
+		return nil, false
+	}
+	value := NewIntValue(it.rangeValue.Elem, it.next, it.rangeValue.source)
+	if it.remaining == 0 {
+		it.done = true
+	} else {
+		it.remaining--
+		it.next += it.rangeValue.Step
+	}
+	return value, true
+}
@@ -0,0 +1,145 @@
+package env
+
+import (
+	"math"
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+// testRange builds start..end, or start..=end, counting by step
+func testRange(elem BaseType, start, end int64, inclusive bool, step int64) *RangeValue {
+	r := NewRangeValue(elem, start, end, inclusive, utils.String{})
+	if step != 1 {
+		r, _ = r.WithStep(step, utils.String{})
+	}
+	return r
+}
+
+// u64 returns the bits of a u64 as ranges store them, see IntValue
+func u64(n uint64) int64 {
+	return int64(n)
+}
+
+func TestRangeCount(t *testing.T) {
+	tests := []struct {
+		r    *RangeValue
+		want string
+	}{
+		{testRange(I32, 0, 10, false, 1), "10"},
+		{testRange(I32, 0, 10, true, 1), "11"},
+		{testRange(I32, 5, 5, false, 1), "0"},
+		{testRange(I32, 5, 5, true, 1), "1"},
+		{testRange(I32, 10, 0, false, 1), "0"},
+		{testRange(I32, 0, 10, false, 3), "4"},
+		{testRange(I32, 0, 9, false, 3), "3"},
+		{testRange(I32, 0, 9, true, 3), "4"},
+		{testRange(I32, 10, 0, false, -3), "4"},
+		{testRange(I32, 10, 0, true, -5), "3"},
+		{testRange(I32, 0, 10, false, -1), "0"},
+		{testRange(I64, math.MinInt64, 0, false, 1), "9223372036854775808"},
+		{testRange(I64, math.MinInt64, math.MaxInt64, true, 1), "18446744073709551616"},
+		{testRange(I64, math.MaxInt64, math.MinInt64, true, math.MinInt64), "2"},
+		{testRange(U64, 0, u64(10000000000000000000), false, 1), "10000000000000000000"},
+		{testRange(U64, 0, u64(math.MaxUint64), true, 1), "18446744073709551616"},
+		{testRange(U64, u64(math.MaxUint64-5), u64(math.MaxUint64), true, 2), "3"},
+	}
+	for _, test := range tests {
+		if got := test.r.Count().String(); got != test.want {
+			t.Errorf("%s has %s elements, want %s", test.r, got, test.want)
+		}
+		n, ok := test.r.Len()
+		if fits := test.r.Count().IsInt64(); ok != fits || (ok && test.r.Count().Int64() != n) {
+			t.Errorf("%s has length %d, %v, want %s", test.r, n, ok, test.want)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		r    *RangeValue
+		n    int64
+		want bool
+	}{
+		{testRange(I32, 0, 10, false, 1), 0, true},
+		{testRange(I32, 0, 10, false, 1), 9, true},
+		{testRange(I32, 0, 10, false, 1), 10, false},
+		{testRange(I32, 0, 10, true, 1), 10, true},
+		{testRange(I32, 0, 10, false, 1), -1, false},
+		{testRange(I32, 0, 10, false, 3), 9, true},
+		{testRange(I32, 0, 10, false, 3), 8, false},
+		{testRange(I32, 10, 0, false, -3), 1, true},
+		{testRange(I32, 10, 0, false, -3), 0, false},
+		{testRange(I32, 10, 0, false, -3), 13, false},
+		{testRange(I64, math.MinInt64, math.MaxInt64, false, 1), 5, true},
+		{testRange(I64, math.MinInt64, math.MaxInt64, false, 1), math.MaxInt64, false},
+		{testRange(I64, math.MinInt64, math.MaxInt64, true, 1), math.MaxInt64, true},
+		{testRange(I64, math.MinInt64, 0, false, 1), math.MinInt64, true},
+		{testRange(I64, math.MaxInt64, math.MinInt64, true, math.MinInt64), -1, true},
+		{testRange(U64, 0, u64(10000000000000000000), false, 1), 5, true},
+		{testRange(U64, 0, u64(10000000000000000000), false, 1), u64(9999999999999999999), true},
+		{testRange(U64, 0, u64(10000000000000000000), false, 1), u64(10000000000000000000), false},
+		{testRange(U64, 0, u64(math.MaxUint64), true, 1), u64(math.MaxUint64), true},
+		{testRange(U64, u64(math.MaxUint64-5), u64(math.MaxUint64), true, 2), u64(math.MaxUint64 - 1), true},
+		{testRange(U64, u64(math.MaxUint64-5), u64(math.MaxUint64), true, 2), u64(math.MaxUint64), false},
+	}
+	for _, test := range tests {
+		if got := test.r.Contains(test.n); got != test.want {
+			t.Errorf("%s contains %s is %v, want %v", test.r, test.r.bound(test.n), got, test.want)
+		}
+	}
+}
+
+func TestRangeIterator(t *testing.T) {
+	tests := []struct {
+		r    *RangeValue
+		want []string
+	}{
+		{testRange(I32, 0, 3, false, 1), []string{"0", "1", "2"}},
+		{testRange(I32, 3, 0, true, -2), []string{"3", "1"}},
+		{testRange(I32, 3, 3, false, 1), nil},
+		{testRange(I64, math.MaxInt64-1, math.MaxInt64, true, 1), []string{"9223372036854775806", "9223372036854775807"}},
+		{testRange(I64, math.MinInt64, 0, false, math.MaxInt64), []string{"-9223372036854775808", "-1"}},
+		{testRange(U64, u64(math.MaxUint64-1), u64(math.MaxUint64), true, 1), []string{"18446744073709551614", "18446744073709551615"}},
+	}
+	for _, test := range tests {
+		var got []string
+		it := test.r.Iterator()
+		for v, ok := it.Next(); ok && len(got) <= len(test.want); v, ok = it.Next() {
+			got = append(got, v.String())
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%s produces %v, want %v", test.r, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s produces %v, want %v", test.r, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRangeSlice(t *testing.T) {
+	tests := []struct {
+		r            *RangeValue
+		from, to, by int64
+		want         string
+	}{
+		{testRange(I32, 0, 10, false, 1), 2, 5, 1, "2..5"},
+		{testRange(I32, 0, 10, false, 2), 1, 4, 1, "2..8..2"},
+		{testRange(I32, 0, 10, false, 1), 3, 3, 1, "3..3"},
+		// the element past the end of the slice does not fit in a u64
+		{testRange(U64, u64(math.MaxUint64-5), u64(math.MaxUint64), true, 1), 4, 6, 1, "18446744073709551614..=18446744073709551615"},
+	}
+	for _, test := range tests {
+		slice := test.r.Slice(test.from, test.to, test.by, utils.String{})
+		if slice.String() != test.want {
+			t.Errorf("%s sliced by %d..%d..%d is %s, want %s", test.r, test.from, test.to, test.by, slice, test.want)
+		}
+		if n, _ := slice.Len(); n != (test.to-test.from+test.by-1)/test.by {
+			t.Errorf("%s sliced by %d..%d..%d has %d elements", test.r, test.from, test.to, test.by, n)
+		}
+	}
+}
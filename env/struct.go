@@ -0,0 +1,99 @@
+package env
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+type Field struct {
+	Name string
+	Type Type
+}
+
+// StructType is the type declared by `comp`, it is also a value:
+// calling it with named fields constructs an instance
+type StructType struct {
+	source   utils.String
+	TypeName string
+	Fields   []Field
+	Impls    []*Impl // the traits implemented by the comp
+}
+
+func NewStructType(name string, fields []Field, source utils.String) *StructType {
+	return &StructType{source: source, TypeName: name, Fields: fields}
+}
+
+func (s *StructType) BaseType() BaseType {
+	return Struct
+}
+
+func (s *StructType) Name() string {
+	return s.TypeName
+}
+
+// FieldIndex returns the position of the named field, or -1
+func (s *StructType) FieldIndex(name string) int {
+	for i, field := range s.Fields {
+		if field.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *StructType) Type() Type {
+	return s
+}
+
+func (s *StructType) Source() utils.String {
+	return s.source
+}
+
+func (s *StructType) IsBase() bool {
+	return false
+}
+
+func (s *StructType) String() string {
+	return "<comp " + s.TypeName + ">"
+}
+
+// StructValue is an instance of a comp, shared by reference like lists
+type StructValue struct {
+	source     utils.String
+	StructType *StructType
+	Fields     []Value
+}
+
+func NewStructValue(structType *StructType, fields []Value, source utils.String) *StructValue {
+	return &StructValue{source: source, StructType: structType, Fields: fields}
+}
+
+func (s *StructValue) Type() Type {
+	return s.StructType
+}
+
+func (s *StructValue) Source() utils.String {
+	return s.source
+}
+
+func (s *StructValue) IsBase() bool {
+	return false
+}
+
+func (s *StructValue) String() string {
+	fields := make([]string, len(s.Fields))
+	for i, field := range s.StructType.Fields {
+		fields[i] = field.Name + ": " + s.Fields[i].String()
+	}
+	return s.StructType.TypeName + "(" + strings.Join(fields, ", ") + ")"
+}
+
+// Get returns the value of the named field
+func (s *StructValue) Get(name string) (Value, bool) {
+	i := s.StructType.FieldIndex(name)
+	if i < 0 {
+		return nil, false
+	}
+	return s.Fields[i], true
+}
@@ -0,0 +1,303 @@
+package env
+
+import (
+	"fmt"
+
+	"com.loop.anonx3247/utils"
+)
+
+// TraitType is an abstract interface declared by `abs`, it bounds the type parameters of generic
+// functions as in `fn sum<T implements Addition>`. Like StructType it is also a value.
+// The types of its methods and constants mention the implementing type as Self.
+type TraitType struct {
+	source    utils.String
+	TraitName string
+	Methods   []Field // required methods and their function types
+	Constants []Field // associated constants, `zero` in `T.zero`
+
+	base      func(t Type) bool  // the types implementing a built-in trait without an impl
+	baseImpls map[BaseType]*Impl // impls of a user trait for base types
+}
+
+// Self is the implementing type in the declaration of a trait
+var Self = NamedType{TypeName: "Self"}
+
+func NewTraitType(name string, methods, constants []Field, source utils.String) *TraitType {
+	return &TraitType{source: source, TraitName: name, Methods: methods, Constants: constants}
+}
+
+func (t *TraitType) BaseType() BaseType {
+	return Trait
+}
+
+func (t *TraitType) Name() string {
+	return t.TraitName
+}
+
+func (t *TraitType) Type() Type {
+	return t
+}
+
+func (t *TraitType) Source() utils.String {
+	return t.source
+}
+
+func (t *TraitType) IsBase() bool {
+	return false
+}
+
+func (t *TraitType) String() string {
+	return "<abs " + t.TraitName + ">"
+}
+
+// IsBuiltin reports whether the trait is one of BuiltinTraits
+func (t *TraitType) IsBuiltin() bool {
+	return t.base != nil
+}
+
+// ImplementedBy reports whether values of type x have the operations of the trait
+func (t *TraitType) ImplementedBy(x Type) bool {
+	if x == nil {
+		return true
+	}
+	if t.base != nil && t.base(x) {
+		return true
+	}
+	_, ok := FindImpl(x, t)
+	return ok
+}
+
+// HasConstant reports whether the trait declares the associated constant name
+func (t *TraitType) HasConstant(name string) bool {
+	for _, constant := range t.Constants {
+		if constant.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodType returns the type of the method name for values of type x
+func (t *TraitType) MethodType(name string, x Type) (FunctionType, bool) {
+	for _, method := range t.Methods {
+		if method.Name == name {
+			return WithSelf(method.Type, x).(FunctionType), true
+		}
+	}
+	return FunctionType{}, false
+}
+
+// ConstantType returns the type of the constant name for type x
+func (t *TraitType) ConstantType(name string, x Type) (Type, bool) {
+	for _, constant := range t.Constants {
+		if constant.Name == name {
+			return WithSelf(constant.Type, x), true
+		}
+	}
+	return nil, false
+}
+
+// WithSelf replaces Self by x in the type t of a method or a constant of a trait
+func WithSelf(t Type, x Type) Type {
+	return SubstituteNamed(t, func(name string) (Type, bool) {
+		return x, name == Self.TypeName
+	})
+}
+
+// Constant returns the associated constant name of type x, which implements the trait
+func (t *TraitType) Constant(x Type, name string, source utils.String) (Value, error) {
+	if impl, ok := FindImpl(x, t); ok {
+		if value, ok := impl.Constants[name]; ok {
+			return value, nil
+		}
+	}
+	if !t.HasConstant(name) || x.BaseType() > Char {
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("%s has no constant %s", t.TraitName, name)}
+	}
+	if x.BaseType() == Str {
+		// the zero of Addition, the only constant of str
+		return NewStrValue("", source), nil
+	}
+	literal := map[string]string{"zero": "0", "one": "1"}[name]
+	return NumberFromLiteral(literal, x.BaseType(), source)
+}
+
+func isNumericBase(t Type) bool {
+	return t.BaseType() <= F64
+}
+
+func isAddableBase(t Type) bool {
+	return t.BaseType() <= F64 || t.BaseType() == Str
+}
+
+func isOrderedBase(t Type) bool {
+	return t.BaseType() <= F64 || t.BaseType() == Char
+}
+
+// isEquatable reports whether `==` compares values of type t: base values, ranges, and lists,
+// tuples, optionals, comps and enums holding equatable values, which are compared element by element
+func isEquatable(t Type) bool {
+	return equatable(t, map[Type]bool{})
+}
+
+// equatable is isEquatable, seen holds the comps and enums being checked, which may contain themselves
+func equatable(t Type, seen map[Type]bool) bool {
+	switch t := t.(type) {
+	case ListType:
+		return equatable(t.Elem, seen)
+	case OptionalType:
+		return t.Elem == nil || equatable(t.Elem, seen)
+	case TupleType:
+		return allEquatable(t.Elems, seen)
+	case *StructType:
+		if seen[t] {
+			return true
+		}
+		seen[t] = true
+		for _, field := range t.Fields {
+			if !equatable(field.Type, seen) {
+				return false
+			}
+		}
+		return true
+	case *EnumType:
+		if seen[t] {
+			return true
+		}
+		seen[t] = true
+		for _, variant := range t.Variants {
+			if !allEquatable(variant.Payload, seen) {
+				return false
+			}
+		}
+		return true
+	case RangeType, NamedType:
+		return true
+	}
+	return t.BaseType() <= Char
+}
+
+func allEquatable(types []Type, seen map[Type]bool) bool {
+	for _, t := range types {
+		if !equatable(t, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// operatorMethod is the type of the method overloading a binary operator
+func operatorMethod(name string, ret Type) []Field {
+	return []Field{{Name: name, Type: FunctionType{Params: []Type{Self, Self}, Return: ret}}}
+}
+
+// BuiltinTraits are the traits of the operators, a type implementing Addition supports `+`.
+// Comps and enums overload an operator by implementing its trait, see ast.OperatorTraits.
+var BuiltinTraits = []*TraitType{
+	{TraitName: "Addition", Methods: operatorMethod("add", Self), Constants: []Field{{Name: "zero", Type: Self}}, base: isAddableBase},
+	{TraitName: "Subtraction", Methods: operatorMethod("sub", Self), base: isNumericBase},
+	{TraitName: "Multiplication", Methods: operatorMethod("mul", Self), Constants: []Field{{Name: "one", Type: Self}}, base: isNumericBase},
+	{TraitName: "Division", Methods: operatorMethod("div", Self), base: isNumericBase},
+	{TraitName: "Equality", Methods: operatorMethod("eq", Bool), base: isEquatable},
+	{TraitName: "Ordering", Methods: operatorMethod("less", Bool), base: isOrderedBase},
+}
+
+// BuiltinTrait returns the built-in trait called name
+func BuiltinTrait(name string) *TraitType {
+	for _, trait := range BuiltinTraits {
+		if trait.TraitName == name {
+			return trait
+		}
+	}
+	return nil
+}
+
+// Impl is an `impl Trait for Type` block. Comps and enums keep their impls, the impls of base types
+// are kept by their trait, which cannot be a built-in one.
+type Impl struct {
+	Trait     *TraitType
+	For       Type
+	Methods   map[string]Value // *FunctionValue, nil for the impls known to the checker
+	Constants map[string]Value
+}
+
+// Impls returns the impls of a comp or an enum, nil for other types
+func Impls(t Type) []*Impl {
+	switch t := t.(type) {
+	case *StructType:
+		return t.Impls
+	case *EnumType:
+		return t.Impls
+	}
+	return nil
+}
+
+// FindImpl returns the impl of trait for type t
+func FindImpl(t Type, trait *TraitType) (*Impl, bool) {
+	switch t.(type) {
+	case *StructType, *EnumType:
+		for _, impl := range Impls(t) {
+			if impl.Trait == trait {
+				return impl, true
+			}
+		}
+		return nil, false
+	}
+	if t == nil || t.BaseType() > Char {
+		return nil, false
+	}
+	impl, ok := trait.baseImpls[t.BaseType()]
+	return impl, ok
+}
+
+// Implement records impl, it returns false when its type already has an impl of the trait.
+// A comp can implement Equality to replace the comparison of its fields.
+func Implement(impl *Impl) bool {
+	if _, ok := FindImpl(impl.For, impl.Trait); ok {
+		return false
+	}
+	switch t := impl.For.(type) {
+	case *StructType:
+		t.Impls = append(t.Impls, impl)
+	case *EnumType:
+		t.Impls = append(t.Impls, impl)
+	default:
+		if impl.Trait.baseImpls == nil {
+			impl.Trait.baseImpls = map[BaseType]*Impl{}
+		}
+		impl.Trait.baseImpls[t.BaseType()] = impl
+	}
+	return true
+}
+
+// Traits returns the traits visible from e
+func (e *Env) Traits() []*TraitType {
+	traits := []*TraitType{}
+	for scope := e; scope != nil; scope = scope.parent {
+		for _, v := range scope.vars {
+			if trait, ok := v.Value.(*TraitType); ok {
+				traits = append(traits, trait)
+			}
+		}
+	}
+	return traits
+}
+
+// Method finds the method name given to values of type t by an impl, the impls of base types
+// are found through the traits visible from e
+func (e *Env) Method(t Type, name string) (Value, bool) {
+	impls := Impls(t)
+	if impls == nil {
+		for _, trait := range e.Traits() {
+			if impl, ok := FindImpl(t, trait); ok {
+				impls = append(impls, impl)
+			}
+		}
+	}
+	for _, impl := range impls {
+		if method, ok := impl.Methods[name]; ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
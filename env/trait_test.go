@@ -0,0 +1,42 @@
+package env
+
+import (
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+func TestEqualityImplementedBy(t *testing.T) {
+	point := NewStructType("Point", []Field{{Name: "x", Type: I32}, {Name: "y", Type: I32}}, utils.String{})
+	callback := NewStructType("Callback", []Field{{Name: "f", Type: FunctionType{Params: []Type{I32}}}}, utils.String{})
+	tree := NewEnumType("Tree", nil, utils.String{})
+	tree.Variants = []Variant{{Name: "Leaf"}, {Name: "Node", Payload: []Type{I32, OptionalType{Elem: tree}}}}
+	tests := []struct {
+		t    Type
+		want bool
+	}{
+		{I32, true},
+		{F64, true},
+		{Str, true},
+		{Char, true},
+		{Bool, true},
+		{TupleType{Elems: []Type{I32, I32}}, true},
+		{ListType{Elem: Str}, true},
+		{ListType{Elem: TupleType{Elems: []Type{I32, Char}}}, true},
+		{OptionalType{Elem: I32}, true},
+		{RangeType{Elem: I64}, true},
+		{point, true},
+		{tree, true},
+		{FunctionType{Params: []Type{I32}}, false},
+		{TupleType{Elems: []Type{I32, FunctionType{}}}, false},
+		{ListType{Elem: FunctionType{}}, false},
+		{callback, false},
+		{ListType{Elem: callback}, false},
+	}
+	equality := BuiltinTrait("Equality")
+	for _, test := range tests {
+		if got := equality.ImplementedBy(test.t); got != test.want {
+			t.Errorf("Equality implemented by %s is %v, want %v", test.t.Name(), got, test.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package env
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+type TupleType struct {
+	Elems []Type
+}
+
+func (t TupleType) BaseType() BaseType {
+	return Tuple
+}
+
+func (t TupleType) Name() string {
+	elems := make([]string, len(t.Elems))
+	for i, elem := range t.Elems {
+		elems[i] = typeName(elem)
+	}
+	if len(elems) == 1 {
+		return "(" + elems[0] + ",)"
+	}
+	return "(" + strings.Join(elems, ", ") + ")"
+}
+
+// TupleValue is a fixed size group of values of possibly different types
+type TupleValue struct {
+	source utils.String
+	Items  []Value
+}
+
+func NewTupleValue(items []Value, source utils.String) *TupleValue {
+	return &TupleValue{source: source, Items: items}
+}
+
+func (t *TupleValue) Type() Type {
+	elems := make([]Type, len(t.Items))
+	for i, item := range t.Items {
+		elems[i] = TypeOf(item)
+	}
+	return TupleType{Elems: elems}
+}
+
+func (t *TupleValue) Source() utils.String {
+	return t.source
+}
+
+func (t *TupleValue) IsBase() bool {
+	return false
+}
+
+func (t *TupleValue) String() string {
+	items := make([]string, len(t.Items))
+	for i, item := range t.Items {
+		items[i] = item.String()
+	}
+	if len(items) == 1 {
+		return "(" + items[0] + ",)"
+	}
+	return "(" + strings.Join(items, ", ") + ")"
+}
@@ -11,5 +11,161 @@ type Value interface {
 
 type Type interface {
 	BaseType() BaseType
+	Name() string
 	// TupleType, FunctionType, etc.
 }
+
+// SameType reports whether values of type a can be used where b is expected.
+// A nil type stands for an unannotated (dynamic) type and matches anything.
+// A T or none can be used where a T? is expected, but a T? cannot be used as a T.
+func SameType(a, b Type) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	if bt, ok := b.(OptionalType); ok {
+		if at, ok := a.(OptionalType); ok {
+			return SameType(at.Elem, bt.Elem)
+		}
+		return SameType(a, bt.Elem)
+	}
+	if a.BaseType() == Named || b.BaseType() == Named {
+		// user types referenced by name are resolved by comparing names
+		return a.Name() == b.Name()
+	}
+	if a.BaseType() != b.BaseType() {
+		return false
+	}
+	switch bt := b.(type) {
+	case FunctionType:
+		at, ok := a.(FunctionType)
+		if !ok || len(at.Params) != len(bt.Params) || at.Defaults != bt.Defaults || len(at.Named) != len(bt.Named) {
+			return false
+		}
+		for i := range at.Params {
+			if !SameType(at.Params[i], bt.Params[i]) {
+				return false
+			}
+		}
+		for i := range at.Named {
+			if at.Named[i].Name != bt.Named[i].Name || at.Named[i].Optional != bt.Named[i].Optional || !SameType(at.Named[i].Type, bt.Named[i].Type) {
+				return false
+			}
+		}
+		return SameType(at.Return, bt.Return)
+	case RangeType:
+		at, ok := a.(RangeType)
+		return ok && at.Elem == bt.Elem
+	case ListType:
+		at, ok := a.(ListType)
+		return ok && SameType(at.Elem, bt.Elem)
+	case TupleType:
+		at, ok := a.(TupleType)
+		if !ok || len(at.Elems) != len(bt.Elems) {
+			return false
+		}
+		for i := range at.Elems {
+			if !SameType(at.Elems[i], bt.Elems[i]) {
+				return false
+			}
+		}
+	case *StructType, *EnumType, *TraitType:
+		return a.Name() == b.Name()
+	}
+	return true
+}
+
+// NamedType is a reference to a user defined type in an annotation, such as `p: Person`
+type NamedType struct {
+	TypeName string
+	Module   string // module qualifying the name, `shapes` in `s: shapes.Square`
+}
+
+func (n NamedType) BaseType() BaseType {
+	return Named
+}
+
+func (n NamedType) Name() string {
+	return n.TypeName
+}
+
+// Copy returns v with its comps, lists, tuples and enum values copied, nested ones included. They
+// are values: binding one to a variable or storing it in a field or an element copies it, so that
+// changing it through a variable never changes what another variable holds. Only a mut parameter
+// shares the variable passed to it.
+func Copy(v Value) Value {
+	switch v := v.(type) {
+	case *StructValue:
+		copied := *v
+		copied.Fields = copyAll(v.Fields)
+		return &copied
+	case *ListValue:
+		copied := *v
+		copied.Items = copyAll(v.Items)
+		return &copied
+	case *TupleValue:
+		copied := *v
+		copied.Items = copyAll(v.Items)
+		return &copied
+	case *EnumValue:
+		copied := *v
+		copied.Payload = copyAll(v.Payload)
+		return &copied
+	}
+	return v
+}
+
+// MutableInPlace reports whether v holds a comp or a list, which assignments to fields and elements
+// and push and pop change in place
+func MutableInPlace(v Value) bool {
+	switch v := v.(type) {
+	case *StructValue, *ListValue:
+		return true
+	case *TupleValue:
+		return anyMutableInPlace(v.Items)
+	case *EnumValue:
+		return anyMutableInPlace(v.Payload)
+	}
+	return false
+}
+
+func anyMutableInPlace(values []Value) bool {
+	for _, v := range values {
+		if MutableInPlace(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyAll(values []Value) []Value {
+	if values == nil {
+		return nil
+	}
+	copied := make([]Value, len(values))
+	for i, v := range values {
+		copied[i] = Copy(v)
+	}
+	return copied
+}
+
+// TypeOf returns the type of v, base values report their BaseType rather than themselves
+func TypeOf(v Value) Type {
+	if v == nil {
+		return nil
+	}
+	if v.IsBase() {
+		return v.Type().BaseType()
+	}
+	return v.Type()
+}
+
+// Iterable values can be looped over with `for ... in`
+type Iterable interface {
+	Value
+	Iterator() Iterator
+}
+
+type Iterator interface {
+	// Next returns the next element, or false once the iteration is over
+	Next() (Value, bool)
+}
@@ -3,38 +3,47 @@ package lexer
 import (
 	"regexp"
 	"sort"
+	"unicode"
+	"unicode/utf8"
 
 	"com.loop.anonx3247/utils"
 )
 
 var (
-	NUMBER_RE        = regexp.MustCompile(`^\d+(\.\d+)?([eE][-]?\d+)?`)
-	IDENTIFIER_RE    = regexp.MustCompile(`^[a-z_][a-zA-Z0-9_]*`)
-	GENERIC_RE       = regexp.MustCompile(`^[A-Z]`)
-	USER_DEFINED_RE  = regexp.MustCompile(`^[A-Z][a-zA-Z0-9_]+`)
-	STRING_RE_SINGLE = regexp.MustCompile(`^'([^']|\\')+'`)
-	STRING_RE_DOUBLE = regexp.MustCompile(`^"([^"]|\\")+"`)
-	STRING_RE_RAW    = regexp.MustCompile("^`([^`]|\\`)+`")
+	IDENTIFIER_RE   = regexp.MustCompile(`^[\p{Ll}\p{Lo}_][\p{L}\p{N}_]*`)
+	GENERIC_RE      = regexp.MustCompile(`^\p{Lu}`)
+	USER_DEFINED_RE = regexp.MustCompile(`^\p{Lu}[\p{L}\p{N}_]+`)
 
 	SINGLE_LINE_COMMENT_RE = regexp.MustCompile(`^--.*`)
 	MULTI_LINE_COMMENT_RE  = regexp.MustCompile(`^---`)
 
-	KEYWORDS   = regexp.MustCompile(`^(if|elif|else|while|for|loop|ret|break|continue|match|comp|type|abs|impl|mod|use|import|as|from|fn|let|mut|in|is|and|or|not|true|false|none|self|super|except|new|del|exit)`)
+	KEYWORDS   = regexp.MustCompile(`^(if|elif|else|while|for|loop|ret|break|continue|match|comp|enum|type|abs|impl|implements|mod|module|use|import|as|from|pub|fn|let|mut|in|is|and|or|not|true|false|none|self|super|except|new|del|exit)`)
 	BASE_TYPES = regexp.MustCompile(`^(u8|u16|u32|u64|u128|i8|i16|i32|i64|i128|f32|f64|bool|char|string)`)
-	OPERATORS  = regexp.MustCompile(`^(\(|\)|\{|\}|\[|\]|\:=|\:|\.\.|\+|\+=|-|-=|\*|\*=|/|/=|%|%=|~|~=|&|&=|\||\|=|\^|\^=|#|\.|\,|->|=>|==|!=|>|>=|<|<=|=)`)
+	OPERATORS  = regexp.MustCompile(`^(\(|\)|\{|\}|\[|\]|\:=|\:|\.\.|\+|\+=|-|-=|\*|\*=|/|/=|%|%=|\*\*|\*\*=|~|~=|&|&=|\||\|=|\^|\^=|#|\.|\,|->|=>|==|!=|>|>=|<|<=|=)`)
 )
 
 type Lexer struct {
-	source string
-	pos    int
+	source         string
+	ptr            *string // shared by every token so that sources can be encompassed
+	pos            int
+	interpolations []interpolation // the strings whose `{...}` parts are being lexed, innermost last
 }
 
 func NewLexer(source string) *Lexer {
-	return &Lexer{source: source}
+	l := &Lexer{source: source}
+	l.ptr = &l.source
+	return l
+}
+
+// NewFileLexer lexes source read from file, the errors found in it show the file, see utils.SetFile
+func NewFileLexer(source, file string) *Lexer {
+	l := NewLexer(source)
+	utils.SetFile(l.ptr, file)
+	return l
 }
 
 func (l *Lexer) slice(length int) utils.String {
-	return utils.StringFrom(l.source, l.pos, length)
+	return utils.String{Ptr: l.ptr, Start: l.pos, Length: length}
 }
 
 func (l *Lexer) Tokenize() (TokenList, error) {
@@ -44,8 +53,15 @@ func (l *Lexer) Tokenize() (TokenList, error) {
 		if err != nil {
 			return tokens, err
 		}
+		if token.Type == EOF {
+			// trailing whitespace or comments
+			break
+		}
 		tokens = append(tokens, token)
 	}
+	if n := len(l.interpolations); n > 0 {
+		return tokens, l.unterminatedString(l.interpolations[n-1].start)
+	}
 	return tokens, nil
 }
 
@@ -91,35 +107,63 @@ func (l *Lexer) Next() (Token, error) {
 			return l.Next()
 		}
 
+		if isQuote(l.source[l.pos]) {
+			tok, err := l.lexString(l.source[l.pos], l.pos)
+			offset = tok.Value.Length
+			return tok, err
+		}
+
+		if n := len(l.interpolations); n > 0 && l.source[l.pos] == '}' && l.interpolations[n-1].depth == 0 {
+			str := l.interpolations[n-1]
+			l.interpolations = l.interpolations[:n-1]
+			tok, err := l.lexString(str.quote, str.start)
+			offset = tok.Value.Length
+			return tok, err
+		}
+
 		atom, err := l.tryTokenizeAtom()
 
 		if err == nil {
 			offset = len(atom.Value.String())
+			if n := len(l.interpolations); n > 0 && atom.Type == L_BRACE {
+				l.interpolations[n-1].depth++
+			} else if n > 0 && atom.Type == R_BRACE {
+				l.interpolations[n-1].depth--
+			}
 			return atom, nil
 		}
 
-		for re, t := range map[*regexp.Regexp]TokenType{
-			IDENTIFIER_RE:    IDENTIFIER,
-			USER_DEFINED_RE:  USER_DEFINED,
-			GENERIC_RE:       GENERIC,
-			NUMBER_RE:        NUMBER_LITERAL,
-			STRING_RE_SINGLE: STRING_LITERAL,
-			STRING_RE_DOUBLE: STRING_LITERAL,
-			STRING_RE_RAW:    STRING_LITERAL,
+		// order matters: USER_DEFINED must be tried before GENERIC
+		for _, pattern := range []struct {
+			re *regexp.Regexp
+			t  TokenType
+		}{
+			{IDENTIFIER_RE, IDENTIFIER},
+			{USER_DEFINED_RE, USER_DEFINED},
+			{GENERIC_RE, GENERIC},
 		} {
-			match, offset = l.Match(re)
+			match, offset = l.Match(pattern.re)
 			if match {
-				return Token{Type: t, Value: l.slice(offset)}, nil
+				if word := l.source[l.pos : l.pos+offset]; word == "inf" || word == "nan" {
+					return Token{Type: NUMBER_LITERAL, Value: l.slice(offset)}, nil
+				}
+				return Token{Type: pattern.t, Value: l.slice(offset)}, nil
 			}
 		}
 
-		if l.source[l.pos] == ' ' || l.source[l.pos] == '\t' {
+		if isDigit(l.source[l.pos]) {
+			tok, err := l.lexNumber()
+			offset = tok.Value.Length
+			return tok, err
+		}
+
+		if l.source[l.pos] == ' ' || l.source[l.pos] == '\t' || l.source[l.pos] == '\r' {
 			l.pos++
 			return l.Next()
 		}
 
 		if l.source[l.pos] == '\n' {
-			l.pos++
+			offset = 1
 			return Token{Type: NEWLINE, Value: l.slice(1)}, nil
 		}
 
@@ -149,14 +193,18 @@ func (l *Lexer) tryTokenizeAtom() (Token, error) {
 		{"continue", CONTINUE},
 		{"match", MATCH},
 		{"comp", COMP},
+		{"enum", ENUM},
 		{"type", TYPE},
 		{"abs", ABS},
 		{"impl", IMPL},
+		{"implements", IMPLEMENTS},
 		{"mod", MOD},
+		{"module", MOD}, // as in the README examples
 		{"use", USE},
 		{"import", IMPORT},
 		{"as", AS},
 		{"from", FROM},
+		{"pub", PUB},
 		{"fn", FN},
 		{"let", LET},
 		{"mut", MUT},
@@ -197,6 +245,7 @@ func (l *Lexer) tryTokenizeAtom() (Token, error) {
 		{":", COLON},
 		{":=", COLON_ASSIGN},
 		{"..", RANGE},
+		{"..=", RANGE_INCLUSIVE},
 		{"+", PLUS},
 		{"+=", PLUS_ASSIGN},
 		{"-", MINUS},
@@ -207,6 +256,8 @@ func (l *Lexer) tryTokenizeAtom() (Token, error) {
 		{"/=", DIVIDE_ASSIGN},
 		{"%", MODULO},
 		{"%=", MODULO_ASSIGN},
+		{"**", POWER},
+		{"**=", POWER_ASSIGN},
 		{"?", OPTIONAL},
 		{"?=", OPTIONAL_ASSIGN},
 		{"!", ERROR_MARK},
@@ -240,9 +291,18 @@ func (l *Lexer) tryTokenizeAtom() (Token, error) {
 
 	for _, word := range words {
 		if len(l.source[l.pos:]) >= len(word.Word) && l.source[l.pos:l.pos+len(word.Word)] == word.Word {
+			// keywords must not be the prefix of a longer identifier (e.g. `format`)
+			next, _ := utf8.DecodeRuneInString(l.source[l.pos+len(word.Word):])
+			if isWordChar(rune(word.Word[len(word.Word)-1])) && isWordChar(next) {
+				continue
+			}
 			return Token{word.Type, l.slice(len(word.Word))}, nil
 		}
 	}
 
 	return Token{_ANY_TOKEN, utils.String{}}, l.error("unknown token")
 }
+
+func isWordChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,105 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"com.loop.anonx3247/utils"
+)
+
+var numberSuffixes = []string{"u8", "u16", "u32", "u64", "i8", "i16", "i32", "i64", "f32", "f64"}
+
+// number bases introduced by a prefix, `0xff`, `0o17`, `0b1010`
+var numberPrefixes = map[string]struct {
+	name   string
+	digits string
+}{
+	"0x": {"hexadecimal", "0123456789abcdefABCDEF"},
+	"0o": {"octal", "01234567"},
+	"0b": {"binary", "01"},
+}
+
+// lexNumber lexes a number literal: decimal digits with an optional fraction and exponent, `1.5e+3`,
+// or integer digits after a base prefix, `0xff`. Underscores may separate digits, `1_000`, and a type
+// suffix may follow, `255u8`. Float suffixes are not allowed after a prefix, where f is a digit.
+func (l *Lexer) lexNumber() (Token, error) {
+	i := l.pos
+	if prefix, ok := numberPrefixes[l.source[i:min(i+2, len(l.source))]]; ok {
+		end, err := l.lexDigits(i+2, prefix.digits)
+		if err != nil {
+			return Token{}, err
+		}
+		if end == i+2 {
+			return Token{}, l.errorAt(end, fmt.Sprintf("expected %s digits after %s", prefix.name, l.source[i:i+2]))
+		}
+		return l.numberEnd(end, prefix.name, numberSuffixes[:8])
+	}
+	end, err := l.lexDigits(i, "0123456789")
+	if err != nil {
+		return Token{}, err
+	}
+	if end+1 < len(l.source) && l.source[end] == '.' && isDigit(l.source[end+1]) {
+		if end, err = l.lexDigits(end+1, "0123456789"); err != nil {
+			return Token{}, err
+		}
+	}
+	if end < len(l.source) && (l.source[end] == 'e' || l.source[end] == 'E') {
+		exponent := end + 1
+		if exponent < len(l.source) && (l.source[exponent] == '+' || l.source[exponent] == '-') {
+			exponent++
+		}
+		if exponent == len(l.source) || !isDigit(l.source[exponent]) {
+			return Token{}, l.errorAt(exponent, "expected digits in the exponent")
+		}
+		if end, err = l.lexDigits(exponent, "0123456789"); err != nil {
+			return Token{}, err
+		}
+	}
+	return l.numberEnd(end, "decimal", numberSuffixes)
+}
+
+// lexDigits reads the digits starting at start and returns where they end. Underscores must
+// separate two digits.
+func (l *Lexer) lexDigits(start int, digits string) (int, error) {
+	i := start
+	for ; i < len(l.source); i++ {
+		c := l.source[i]
+		if c == '_' {
+			if i == start || l.source[i-1] == '_' {
+				return 0, l.errorAt(i, "_ must separate two digits")
+			}
+			continue
+		}
+		if !strings.ContainsRune(digits, rune(c)) {
+			break
+		}
+	}
+	if i > start && l.source[i-1] == '_' {
+		return 0, l.errorAt(i-1, "_ must separate two digits")
+	}
+	return i, nil
+}
+
+// numberEnd reads the optional suffix of the number literal whose digits end at end, the literal
+// must not be directly followed by a letter or a digit
+func (l *Lexer) numberEnd(end int, base string, suffixes []string) (Token, error) {
+	for _, suffix := range suffixes {
+		if strings.HasPrefix(l.source[end:], suffix) {
+			end += len(suffix)
+			break
+		}
+	}
+	if r, _ := utf8.DecodeRuneInString(l.source[end:]); isWordChar(r) {
+		return Token{}, l.errorAt(end, fmt.Sprintf("invalid character %q in %s literal", r, base))
+	}
+	return Token{Type: NUMBER_LITERAL, Value: l.slice(end - l.pos)}, nil
+}
+
+func (l *Lexer) errorAt(pos int, message string) utils.Error {
+	return utils.Error{Source: utils.String{Ptr: l.ptr, Start: pos, Length: 1}, Message: message}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
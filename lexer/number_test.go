@@ -0,0 +1,88 @@
+package lexer
+
+import (
+	"testing"
+
+	"com.loop.anonx3247/utils"
+)
+
+func TestLexNumber(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string // the text of the first token, a number literal
+	}{
+		{"0", "0"},
+		{"42", "42"},
+		{"1_000_000", "1_000_000"},
+		{"3.14", "3.14"},
+		{"1.5e3", "1.5e3"},
+		{"1.5E+3", "1.5E+3"},
+		{"2e-10", "2e-10"},
+		{"1_0.2_5e1_0", "1_0.2_5e1_0"},
+		{"0xff", "0xff"},
+		{"0xDEAD_BEEF", "0xDEAD_BEEF"},
+		{"0o17", "0o17"},
+		{"0b1010_0101", "0b1010_0101"},
+		{"255u8", "255u8"},
+		{"1_000i64", "1_000i64"},
+		{"2.5f32", "2.5f32"},
+		{"1e3f64", "1e3f64"},
+		{"0xffu16", "0xffu16"},
+		{"0b1i8", "0b1i8"},
+		// f is a hexadecimal digit, so float suffixes cannot follow a prefix
+		{"0xfff32", "0xfff32"},
+		{"inf", "inf"},
+		{"nan", "nan"},
+		// a range is not a fraction, `0..10` starts with 0
+		{"0..10", "0"},
+		// `t.0.1` reads the fields of nested tuples
+		{"1.2.3", "1.2"},
+		{"7 + 1", "7"},
+		{"5)", "5"},
+	}
+	for _, test := range tests {
+		tokens, err := NewLexer(test.source).Tokenize()
+		if err != nil {
+			t.Errorf("lexing %q failed: %v", test.source, err)
+			continue
+		}
+		if len(tokens) == 0 || tokens[0].Type != NUMBER_LITERAL || tokens[0].Value.String() != test.want {
+			t.Errorf("lexing %q gives %v, want the number literal %q first", test.source, tokens, test.want)
+		}
+	}
+}
+
+func TestLexNumberErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		message string
+		at      int // the position the error points at
+	}{
+		{"1__000", "_ must separate two digits", 2},
+		{"1000_", "_ must separate two digits", 4},
+		{"0x_ff", "_ must separate two digits", 2},
+		{"0x", "expected hexadecimal digits after 0x", 2},
+		{"0xg", "expected hexadecimal digits after 0x", 2},
+		{"0o8", "expected octal digits after 0o", 2},
+		{"0b102", "invalid character '2' in binary literal", 4},
+		{"0o78", "invalid character '8' in octal literal", 3},
+		{"1e", "expected digits in the exponent", 2},
+		{"1e+", "expected digits in the exponent", 3},
+		{"1.5ex", "expected digits in the exponent", 4},
+		{"0b1f32", "invalid character 'f' in binary literal", 3},
+		{"12abc", "invalid character 'a' in decimal literal", 2},
+		{"255u9", "invalid character 'u' in decimal literal", 3},
+		{"1i8x", "invalid character 'x' in decimal literal", 3},
+	}
+	for _, test := range tests {
+		_, err := NewLexer(test.source).Tokenize()
+		e, ok := err.(utils.Error)
+		if !ok {
+			t.Errorf("lexing %q gives %v, want error %q", test.source, err, test.message)
+			continue
+		}
+		if e.Message != test.message || e.Source.Start != test.at {
+			t.Errorf("lexing %q reports %q at %d, want %q at %d", test.source, e.Message, e.Source.Start, test.message, test.at)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package lexer
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/utils"
+)
+
+// interpolation is a string whose `{...}` part is being lexed
+type interpolation struct {
+	quote byte
+	start int // position of the opening quote
+	depth int // braces opened inside the part, the string resumes at the `}` closing it
+}
+
+func isQuote(c byte) bool {
+	return c == '\'' || c == '"' || c == '`'
+}
+
+// lexString lexes a string delimited by quote, from its opening quote or from the `}` closing one of
+// its interpolations, the string then started at start. The token ends at the closing quote, or at the `{` opening the next
+// interpolation, in which case the string resumes once the matching `}` is reached, see Next.
+// Raw strings, delimited by backticks, have neither escapes nor interpolations.
+func (l *Lexer) lexString(quote byte, start int) (Token, error) {
+	resumed := l.source[l.pos] == '}'
+	for i := l.pos + 1; i < len(l.source); i++ {
+		switch c := l.source[i]; {
+		case c == '\\' && quote != '`':
+			i++
+			if strings.HasPrefix(l.source[i:], "u{") {
+				// the braces of a unicode escape do not open an interpolation
+				for i++; i+1 < len(l.source) && l.source[i+1] != '}' && l.source[i+1] != quote; i++ {
+				}
+			}
+		case c == quote:
+			if resumed {
+				return Token{Type: STRING_TAIL, Value: l.slice(i + 1 - l.pos)}, nil
+			}
+			return Token{Type: STRING_LITERAL, Value: l.slice(i + 1 - l.pos)}, nil
+		case c == '{' && quote != '`':
+			l.interpolations = append(l.interpolations, interpolation{quote: quote, start: start})
+			if resumed {
+				return Token{Type: STRING_MIDDLE, Value: l.slice(i + 1 - l.pos)}, nil
+			}
+			return Token{Type: STRING_HEAD, Value: l.slice(i + 1 - l.pos)}, nil
+		}
+	}
+	return Token{}, l.unterminatedString(start)
+}
+
+func (l *Lexer) unterminatedString(start int) error {
+	return utils.Error{Source: utils.String{Ptr: l.ptr, Start: start, Length: 1}, Message: "unterminated string"}
+}
@@ -43,14 +43,17 @@ const (
 	CONTINUE
 	MATCH
 	COMP
+	ENUM
 	TYPE
 	ABS
 	IMPL
+	IMPLEMENTS
 	MOD
 	USE // local import
 	IMPORT
 	AS
 	FROM
+	PUB
 	FN
 	LET
 	MUT
@@ -80,6 +83,7 @@ const (
 	ASSIGN                     // =
 	COLON_ASSIGN               // :=
 	RANGE                      // ..
+	RANGE_INCLUSIVE            // ..=
 	PLUS                       // +
 	PLUS_ASSIGN                // +=
 	MINUS                      // -
@@ -90,6 +94,8 @@ const (
 	DIVIDE_ASSIGN              // /=
 	MODULO                     // %
 	MODULO_ASSIGN              // %=
+	POWER                      // **
+	POWER_ASSIGN               // **=
 	OPTIONAL                   // ?
 	OPTIONAL_ASSIGN            // ?=
 	ERROR_MARK                 // !
@@ -117,8 +123,11 @@ const (
 	LESS_THAN_OR_EQUAL         // <=
 
 	// Literals
-	NUMBER_LITERAL // 123, 123.456, 0x123, 0b10101010
+	NUMBER_LITERAL // 123, 1_000, 123.456, 1e+3, 0x123, 0o17, 0b10101010, inf, nan
 	STRING_LITERAL // "Hello, world!", 'a', 'bye bye', r'\my string'
+	STRING_HEAD    // "Hello, {   the text before the first interpolation of a string
+	STRING_MIDDLE  // } and {     the text between two interpolations
+	STRING_TAIL    // }!"         the text after the last interpolation
 	IDENTIFIER
 
 	NEWLINE
@@ -159,15 +168,15 @@ func (s ShapeType) Matches(token TokenType) bool {
 	case S_CLOSE_BRACKET:
 		check = token == R_BRACKET || token == R_BRACE || token == R_PAREN
 	case S_OPERATOR:
-		check = token == PLUS || token == MINUS || token == MULTIPLY || token == DIVIDE || token == MODULO || token == BITWISE_AND || token == BITWISE_OR || token == BITWISE_XOR || token == BITWISE_NOT || token == BITWISE_LEFT_SHIFT || token == BITWISE_RIGHT_SHIFT || token == EQUAL || token == NOT_EQUAL || token == GREATER_THAN || token == GREATER_THAN_OR_EQUAL || token == LESS_THAN || token == LESS_THAN_OR_EQUAL || token == ADDRESS_OF || token == AND || token == OR || token == NOT
+		check = token == PLUS || token == MINUS || token == MULTIPLY || token == DIVIDE || token == MODULO || token == POWER || token == BITWISE_AND || token == BITWISE_OR || token == BITWISE_XOR || token == BITWISE_NOT || token == BITWISE_LEFT_SHIFT || token == BITWISE_RIGHT_SHIFT || token == EQUAL || token == NOT_EQUAL || token == GREATER_THAN || token == GREATER_THAN_OR_EQUAL || token == LESS_THAN || token == LESS_THAN_OR_EQUAL || token == ADDRESS_OF || token == AND || token == OR || token == NOT
 	case S_UNARY_OPERATOR:
 		check = token == PLUS || token == MINUS || token == BITWISE_NOT || token == ADDRESS_OF || token == NOT
 	case S_BINARY_OPERATOR:
-		check = token == PLUS || token == MINUS || token == MULTIPLY || token == DIVIDE || token == MODULO || token == BITWISE_AND || token == BITWISE_OR || token == BITWISE_XOR || token == BITWISE_NOT || token == BITWISE_LEFT_SHIFT || token == BITWISE_RIGHT_SHIFT || token == EQUAL || token == NOT_EQUAL || token == GREATER_THAN || token == GREATER_THAN_OR_EQUAL || token == LESS_THAN || token == LESS_THAN_OR_EQUAL || token == AND || token == OR
+		check = token == PLUS || token == MINUS || token == MULTIPLY || token == DIVIDE || token == MODULO || token == POWER || token == BITWISE_AND || token == BITWISE_OR || token == BITWISE_XOR || token == BITWISE_NOT || token == BITWISE_LEFT_SHIFT || token == BITWISE_RIGHT_SHIFT || token == EQUAL || token == NOT_EQUAL || token == GREATER_THAN || token == GREATER_THAN_OR_EQUAL || token == LESS_THAN || token == LESS_THAN_OR_EQUAL || token == AND || token == OR || token == RANGE || token == RANGE_INCLUSIVE || token == IN
 	case S_ASSIGN_OPERATOR:
-		check = token == COLON_ASSIGN || token == PLUS_ASSIGN || token == MINUS_ASSIGN || token == MULTIPLY_ASSIGN || token == DIVIDE_ASSIGN || token == MODULO_ASSIGN || token == BITWISE_AND_ASSIGN || token == BITWISE_OR_ASSIGN || token == BITWISE_XOR_ASSIGN || token == BITWISE_LEFT_SHIFT_ASSIGN || token == BITWISE_RIGHT_SHIFT_ASSIGN || token == ASSIGN
+		check = token == COLON_ASSIGN || token == PLUS_ASSIGN || token == MINUS_ASSIGN || token == MULTIPLY_ASSIGN || token == DIVIDE_ASSIGN || token == MODULO_ASSIGN || token == POWER_ASSIGN || token == BITWISE_AND_ASSIGN || token == BITWISE_OR_ASSIGN || token == BITWISE_XOR_ASSIGN || token == BITWISE_LEFT_SHIFT_ASSIGN || token == BITWISE_RIGHT_SHIFT_ASSIGN || token == OPTIONAL_ASSIGN || token == ASSIGN
 	case S_KEYWORD:
-		check = token == IF || token == ELIF || token == ELSE || token == WHILE || token == FOR || token == LOOP || token == RET || token == BREAK || token == CONTINUE || token == MATCH || token == COMP || token == TYPE || token == ABS || token == IMPL || token == MOD || token == USE || token == IMPORT || token == AS || token == FN || token == LET || token == MUT || token == IN || token == IS || token == AND || token == OR || token == NOT || token == EXCEPT || token == NEW || token == DEL || token == EXIT
+		check = token == IF || token == ELIF || token == ELSE || token == WHILE || token == FOR || token == LOOP || token == RET || token == BREAK || token == CONTINUE || token == MATCH || token == COMP || token == ENUM || token == TYPE || token == ABS || token == IMPL || token == IMPLEMENTS || token == MOD || token == USE || token == IMPORT || token == AS || token == FROM || token == PUB || token == FN || token == LET || token == MUT || token == IN || token == IS || token == AND || token == OR || token == NOT || token == EXCEPT || token == NEW || token == DEL || token == EXIT
 	default:
 		check = true
 	}
@@ -181,7 +190,7 @@ func Equal(a, b Token) bool {
 	switch a.Type {
 	case NUMBER_LITERAL:
 		return a.Value.Equal(b.Value) && a.Type == b.Type
-	case STRING_LITERAL:
+	case STRING_LITERAL, STRING_HEAD, STRING_MIDDLE, STRING_TAIL:
 		return a.Value.Equal(b.Value) && a.Type == b.Type
 	case IDENTIFIER:
 		return a.Value.Equal(b.Value) && a.Type == b.Type
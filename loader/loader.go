@@ -0,0 +1,184 @@
+// Package loader finds, checks and evaluates the files of a program and the modules they import.
+// Each module is loaded once, the files importing it share its values.
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/checker"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/parser"
+	"com.loop.anonx3247/utils"
+)
+
+// Extension is the extension of loop source files
+const Extension = ".lp"
+
+type Loader struct {
+	SearchPath []string // directories searched for the modules that are not next to the importing file
+	Overflow   env.OverflowPolicy
+
+	modules map[string]*env.ModuleValue // loaded modules by absolute path
+	loading []loading                   // files being loaded, innermost last
+}
+
+type loading struct {
+	file string
+	name string
+}
+
+func New(searchPath []string, overflow env.OverflowPolicy) *Loader {
+	return &Loader{SearchPath: searchPath, Overflow: overflow, modules: map[string]*env.ModuleValue{}}
+}
+
+// Run loads the file at path as the main module of a program and returns its top level scope
+func (l *Loader) Run(path string) (*env.Env, error) {
+	file, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	module, err := l.load(file, "", utils.String{})
+	if err != nil {
+		return nil, err
+	}
+	return module.Env, nil
+}
+
+// Prepare lets program, a file of dir about to be checked by c and evaluated in e, import modules.
+// The modules it imports at its top level are loaded first, so that c knows their declarations.
+func (l *Loader) Prepare(program *ast.Scope, dir string, c *checker.Checker, e *env.Env) error {
+	importer := fileImporter{loader: l, dir: dir}
+	e.SetImporter(importer)
+	for _, expr := range program.Exprs {
+		n, ok := expr.(ast.ImportExpr)
+		if !ok {
+			continue
+		}
+		module, err := importer.Import(n.Path, n.Local, n.Source())
+		if err != nil {
+			return err
+		}
+		c.DeclareModule(n.Path, n.Local, module.Type().(env.ModuleType))
+	}
+	return nil
+}
+
+// fileImporter imports modules on behalf of the files of a directory
+type fileImporter struct {
+	loader *Loader
+	dir    string
+}
+
+func (i fileImporter) Import(path []string, local bool, source utils.String) (*env.ModuleValue, error) {
+	file, err := i.loader.resolve(path, local, i.dir, source)
+	if err != nil {
+		return nil, err
+	}
+	return i.loader.load(file, path[len(path)-1], source)
+}
+
+// resolve finds the file of the module at path imported from dir, a local import is only looked
+// for in dir
+func (l *Loader) resolve(path []string, local bool, dir string, source utils.String) (string, error) {
+	name := filepath.Join(path...) + Extension
+	dirs := []string{dir}
+	if !local {
+		dirs = append(dirs, l.SearchPath...)
+	}
+	for _, d := range dirs {
+		file, err := filepath.Abs(filepath.Join(d, name))
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file, nil
+		}
+	}
+	message := fmt.Sprintf("cannot find module %s, looked for %s in %s", strings.Join(path, "."), name, strings.Join(dirs, ", "))
+	return "", utils.Error{Source: source, Message: message}
+}
+
+// load evaluates the module in file once, name is the name it is imported by, empty for the main
+// module whose file may declare any name
+func (l *Loader) load(file, name string, source utils.String) (*env.ModuleValue, error) {
+	if module, ok := l.modules[file]; ok {
+		return module, nil
+	}
+	for i, loading := range l.loading {
+		if loading.file == file {
+			cycle := []string{}
+			for _, importing := range l.loading[i:] {
+				cycle = append(cycle, importing.name)
+			}
+			cycle = append(cycle, loading.name)
+			return nil, utils.Error{Source: source, Message: "import cycle: " + strings.Join(cycle, " -> ")}
+		}
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if name == "" {
+			return nil, err
+		}
+		return nil, utils.Error{Source: source, Message: fmt.Sprintf("cannot read module %s: %v", name, err)}
+	}
+	var p *parser.Parser
+	if name == "" {
+		p, err = parser.NewParser(string(content))
+	} else {
+		p, err = parser.NewFileParser(string(content), displayPath(file))
+	}
+	if err != nil {
+		return nil, err
+	}
+	program, err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+	moduleName := strings.TrimSuffix(filepath.Base(file), Extension)
+	if len(program.Exprs) > 0 {
+		if mod, ok := program.Exprs[0].(ast.ModDecl); ok {
+			if name != "" && mod.Name != name {
+				message := fmt.Sprintf("%s declares mod %s, it is imported as %s", filepath.Base(file), mod.Name, name)
+				return nil, utils.Error{Source: source, Message: message}
+			}
+			moduleName = mod.Name
+		}
+	}
+
+	l.loading = append(l.loading, loading{file: file, name: moduleName})
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	c := checker.New()
+	moduleEnv := env.NewEnv()
+	moduleEnv.SetOverflow(l.Overflow)
+	if err := l.Prepare(&program, filepath.Dir(file), c, moduleEnv); err != nil {
+		return nil, err
+	}
+	if err := c.Check(&program); err != nil {
+		return nil, err
+	}
+	if _, err := program.EvalIn(moduleEnv); err != nil {
+		return nil, err
+	}
+	module := env.NewModuleValue(moduleName, file, moduleEnv, c.Module(moduleName), source)
+	l.modules[file] = module
+	return module, nil
+}
+
+// displayPath returns file relative to the working directory when it is below it, as errors show it
+func displayPath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(wd, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return rel
+}
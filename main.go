@@ -2,55 +2,68 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/checker"
 	"com.loop.anonx3247/env"
-	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/loader"
 	"com.loop.anonx3247/parser"
 )
 
 func main() {
+	overflowName := flag.String("overflow", "trap", "what integer arithmetic does when a result does not fit its type: trap, wrap or saturate")
+	searchPath := flag.String("path", "", "directories searched for imported modules, separated by "+string(filepath.ListSeparator))
+	flag.Parse()
+	overflow, ok := env.OverflowPolicyFromName(*overflowName)
+	if !ok {
+		fmt.Printf("Unknown overflow policy '%s', expected trap, wrap or saturate\n", *overflowName)
+		os.Exit(1)
+	}
+	l := loader.New(filepath.SplitList(*searchPath), overflow)
+
 	// Check if a file path is provided as command line argument
-	if len(os.Args) < 2 {
+	if flag.NArg() < 1 {
 		// Enter REPL mode
-		runREPL()
+		runREPL(l)
 		return
 	}
 
 	// Get the file path from command line arguments
-	filePath := os.Args[1]
+	filePath := flag.Arg(0)
 
-	// Read the file content
-	content, err := os.ReadFile(filePath)
-	if err != nil {
+	if _, err := os.Stat(filePath); err != nil {
 		fmt.Printf("Error reading file '%s': %v\n", filePath, err)
 		os.Exit(1)
 	}
 
-	// Convert content to string
-	source := string(content)
-
-	// Create a new lexer with the file content
-	lex := lexer.NewLexer(source)
-
-	// Tokenize the content
-	tokens, err := lex.Tokenize()
-	if err != nil {
-		fmt.Printf("Error tokenizing file: %v\n", err)
-		printTokens(tokens)
+	if err := runFile(filePath, l); err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	// Display the tokens
-	fmt.Printf("Tokenizing file: %s\n", filePath)
-	fmt.Printf("Found %d tokens:\n\n", len(tokens))
+// runFile evaluates a whole program with the modules it imports, then calls its main function if
+// it declares one
+func runFile(path string, l *loader.Loader) error {
+	programEnv, err := l.Run(path)
+	if err != nil {
+		return err
+	}
 
-	printTokens(tokens)
+	if mainFn, ok := programEnv.Get("main"); ok {
+		if _, err := ast.CallValue(mainFn, nil, mainFn.Source()); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func runREPL() {
+func runREPL(l *loader.Loader) {
 	fmt.Println("Loop Language REPL")
 	fmt.Println("Type 'exit' or 'quit' to exit, or press Ctrl+C")
 	fmt.Println()
@@ -58,6 +71,10 @@ func runREPL() {
 	scanner := bufio.NewScanner(os.Stdin)
 
 	replEnv := env.NewEnv()
+	replEnv.SetOverflow(l.Overflow)
+	replChecker := checker.New()
+	// modules imported in the REPL are looked for in the working directory
+	replDir, _ := os.Getwd()
 
 	for {
 		fmt.Print("loop> ")
@@ -92,29 +109,26 @@ func runREPL() {
 			fmt.Printf("Error: %v\n", err)
 			continue
 		}
-		val, err := program.Eval(replEnv)
+		if err := l.Prepare(&program, replDir, replChecker, replEnv); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		if err := replChecker.Check(&program); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		val, err := program.EvalIn(replEnv)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
 		}
 
-		fmt.Println(val)
+		if val != nil {
+			fmt.Println(val)
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		fmt.Printf("Error reading input: %v\n", err)
 	}
 }
-
-func printTokens(tokens []lexer.Token) {
-	for i, token := range tokens {
-		if token.Type == lexer.EOF {
-			fmt.Printf("%d: EOF  ", i+1)
-		} else if token.Type == lexer.NEWLINE {
-			fmt.Printf("%d: NEWLINE ", i+1)
-		} else {
-			fmt.Printf("%d: %d ('%s')  ", i+1, int(token.Type), token.Value.String())
-		}
-	}
-	fmt.Println()
-}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// isConversion reports whether tok starts a conversion, a base type such as `u8` or one of
+// the aliases spelled as identifiers (`str`, `int`, `uint`) followed by `(` or `.mode(`
+func (p *Parser) isConversion(tok lexer.Token) bool {
+	if _, ok := env.BaseTypeFromName(tok.Value.String()); !ok {
+		return false
+	}
+	if tok.Type != lexer.IDENTIFIER {
+		return lexer.S_TYPE.Matches(tok.Type)
+	}
+	next := p.PeekTokens(min(3, len(p.tokens)-p.pos))
+	if len(next) > 0 && next[0].Type == lexer.L_PAREN {
+		return true
+	}
+	if len(next) == 3 && next[0].Type == lexer.PERIOD && next[2].Type == lexer.L_PAREN {
+		_, ok := env.ConversionModeFromName(next[1].Value.String())
+		return ok
+	}
+	return false
+}
+
+// assumes that the type token has already been consumed
+//
+//	u8(x)
+//	u8.wrapping(x)
+func (p *Parser) parseConversion(typeToken lexer.Token) (ast.Expr, error) {
+	t, _ := env.BaseTypeFromName(typeToken.Value.String())
+	mode := env.Checked
+	if _, err := p.TryConsume(lexer.PERIOD); err == nil {
+		name, err := p.TryConsume(lexer.IDENTIFIER)
+		if err != nil {
+			return nil, p.error("expected checked, wrapping or saturating after " + t.Name() + ".")
+		}
+		var ok bool
+		if mode, ok = env.ConversionModeFromName(name.Value.String()); !ok {
+			p.pos--
+			return nil, p.error("unknown conversion mode " + name.Value.String() + ", expected checked, wrapping or saturating")
+		}
+	}
+	if _, err := p.TryConsume(lexer.L_PAREN); err != nil {
+		return nil, p.error("expected ( to convert a value to " + t.Name())
+	}
+	p.SkipNewlines()
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.SkipNewlines()
+	closing, err := p.TryConsume(lexer.R_PAREN)
+	if err != nil {
+		return nil, p.error("expected ) after the value converted to " + t.Name())
+	}
+	return ast.NewConversionExpr(value, t, mode, utils.Encompass(typeToken.Value, closing.Value)), nil
+}
+
+// parseCasts applies `as` casts to an already parsed atom, `x as u8 as f32`
+func (p *Parser) parseCasts(value ast.Expr) (ast.Expr, error) {
+	for {
+		if _, err := p.TryConsume(lexer.AS); err != nil {
+			return value, nil
+		}
+		start := p.pos
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, p.error("expected a type after as")
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		base, ok := t.(env.BaseType)
+		if !ok {
+			p.pos = start
+			return nil, p.error("cannot cast to " + t.Name() + ", only base types can be cast to")
+		}
+		value = ast.NewConversionExpr(value, base, env.Checked, utils.Encompass(value.Source(), tok.Value))
+	}
+}
@@ -2,33 +2,42 @@ package parser
 
 import (
 	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
 	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
 )
 
+// operatorPrecedence orders binary operators from the loosest, `or`, to the tightest, `**`, so that
+// `x in 0..10 and y == 2` is `(x in (0..10)) and (y == 2)`
 var operatorPrecedence = map[lexer.TokenType]int{
-	lexer.EQUAL:                 1,
-	lexer.NOT_EQUAL:             1,
-	lexer.GREATER_THAN:          1,
-	lexer.GREATER_THAN_OR_EQUAL: 1,
-	lexer.LESS_THAN:             1,
-	lexer.LESS_THAN_OR_EQUAL:    1,
-	lexer.AND:                   1,
 	lexer.OR:                    1,
-	lexer.NOT:                   1,
-	lexer.IF:                    2,
-	// lexer.MATCH:                 2,
-	lexer.PLUS:                3,
-	lexer.MINUS:               3,
-	lexer.ADDRESS_OF:          3,
-	lexer.MULTIPLY:            4,
-	lexer.DIVIDE:              4,
-	lexer.MODULO:              4,
-	lexer.BITWISE_AND:         5,
-	lexer.BITWISE_OR:          5,
-	lexer.BITWISE_XOR:         5,
-	lexer.BITWISE_NOT:         5,
-	lexer.BITWISE_LEFT_SHIFT:  6,
-	lexer.BITWISE_RIGHT_SHIFT: 6,
+	lexer.AND:                   2,
+	lexer.EQUAL:                 3,
+	lexer.NOT_EQUAL:             3,
+	lexer.GREATER_THAN:          3,
+	lexer.GREATER_THAN_OR_EQUAL: 3,
+	lexer.LESS_THAN:             3,
+	lexer.LESS_THAN_OR_EQUAL:    3,
+	lexer.NOT:                   3,
+	lexer.IN:                    3,
+	lexer.IS:                    3,
+	lexer.IF:                    4,
+	lexer.RANGE:                 4,
+	lexer.RANGE_INCLUSIVE:       4,
+	lexer.MATCH:                 4,
+	lexer.PLUS:                  5,
+	lexer.MINUS:                 5,
+	lexer.ADDRESS_OF:            5,
+	lexer.MULTIPLY:              6,
+	lexer.DIVIDE:                6,
+	lexer.MODULO:                6,
+	lexer.BITWISE_AND:           7,
+	lexer.BITWISE_OR:            7,
+	lexer.BITWISE_XOR:           7,
+	lexer.BITWISE_NOT:           7,
+	lexer.BITWISE_LEFT_SHIFT:    8,
+	lexer.BITWISE_RIGHT_SHIFT:   8,
+	lexer.POWER:                 9,
 }
 
 func (p *Parser) ParseExpr() (ast.Expr, error) {
@@ -37,31 +46,65 @@ func (p *Parser) ParseExpr() (ast.Expr, error) {
 
 // note that unary expressions are considered atoms, as well as parenthesis
 func (p *Parser) parseAtom() (ast.Expr, error) {
+	atom, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	atom, err = p.parsePostfix(atom)
+	if err != nil {
+		return nil, err
+	}
+
+	// assignments to comp fields and list elements, `me.age += 1`
+	next, err := p.Peek()
+	if err == nil && lexer.S_ASSIGN_OPERATOR.Matches(next.Type) && next.Type != lexer.COLON_ASSIGN {
+		switch atom.(type) {
+		case ast.FieldExpr, ast.IndexExpr:
+			p.Consume()
+			value, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return ast.NewPlaceAssignmentExpr(atom, next, value), nil
+		}
+	}
+	return atom, nil
+}
+
+func (p *Parser) parsePrimary() (ast.Expr, error) {
 	leftToken, err := p.Consume()
 	if err != nil {
 		return nil, err
 	}
 
 	if lexer.S_UNARY_OPERATOR.Matches(leftToken.Type) {
-		expr, err := p.ParseExpr()
-		if err != nil {
-			return nil, err
-		}
-		return ast.UnaryExpr{Op: leftToken.Type, Value: expr}, nil
-	} else if leftToken.Type == lexer.L_PAREN {
-		expr, err := p.ParseExpr()
-		if err != nil {
-			return nil, err
+		if literal, ok, err := p.parseNegativeNumber(leftToken); ok {
+			return literal, err
 		}
-		_, err = p.TryConsume(lexer.R_PAREN)
+		expr, err := p.parseAtom()
 		if err != nil {
 			return nil, err
 		}
-		return ast.ParenExpr{Expr: expr}, nil
+		return ast.NewUnaryExpr(leftToken, expr), nil
+	} else if leftToken.Type == lexer.L_PAREN {
+		return p.parseParenthesised(leftToken)
+	} else if leftToken.Type == lexer.L_BRACE {
+		return p.parseBlock()
+	} else if leftToken.Type == lexer.L_BRACKET {
+		return p.parseList(leftToken)
+	} else if p.isConversion(leftToken) {
+		return p.parseConversion(leftToken)
+	} else if leftToken.Type == lexer.STRING_HEAD {
+		return p.parseInterpolation(leftToken)
+	} else if leftToken.Type == lexer.SELF {
+		return ast.NewIdentifier(leftToken.Value), nil
 	} else if lexer.S_VALUE.Matches(leftToken.Type) {
 		if leftToken.Type == lexer.IDENTIFIER {
 			next, err := p.Peek()
 			if err == nil {
+				if next.Type == lexer.COLON {
+					return p.parseAnnotatedDeclaration(leftToken, leftToken)
+				}
 				if lexer.S_ASSIGN_OPERATOR.Matches(next.Type) {
 					p.Consume()
 					expr, err := p.ParseExpr()
@@ -78,42 +121,265 @@ func (p *Parser) parseAtom() (ast.Expr, error) {
 			return nil, err
 		}
 		return lit, nil
+	} else if leftToken.Type == lexer.USER_DEFINED || leftToken.Type == lexer.GENERIC {
+		return ast.NewIdentifier(leftToken.Value), nil
 	} else if leftToken.Type == lexer.IF {
 		return p.parseIfExpr()
+	} else if leftToken.Type == lexer.FN {
+		return p.parseFunctionDecl(leftToken)
+	} else if leftToken.Type == lexer.RET {
+		return p.parseReturn(leftToken)
+	} else if leftToken.Type == lexer.MUT || leftToken.Type == lexer.LET {
+		return p.parseDeclaration(leftToken)
+	} else if leftToken.Type == lexer.WHILE {
+		return p.parseWhile(leftToken)
+	} else if leftToken.Type == lexer.LOOP {
+		return p.parseLoop(leftToken)
+	} else if leftToken.Type == lexer.FOR {
+		return p.parseFor(leftToken)
+	} else if leftToken.Type == lexer.BREAK {
+		return p.parseBreak(leftToken)
+	} else if leftToken.Type == lexer.CONTINUE {
+		return ast.NewContinueExpr(leftToken.Value), nil
+	} else if leftToken.Type == lexer.COMP {
+		return p.parseComp(leftToken)
+	} else if leftToken.Type == lexer.ENUM {
+		return p.parseEnum(leftToken)
+	} else if leftToken.Type == lexer.MATCH {
+		return p.parseMatch(leftToken)
+	} else if leftToken.Type == lexer.ABS {
+		return p.parseAbs(leftToken)
+	} else if leftToken.Type == lexer.IMPL {
+		return p.parseImpl(leftToken)
+	} else if leftToken.Type == lexer.MOD {
+		return p.parseMod(leftToken)
+	} else if leftToken.Type == lexer.IMPORT || leftToken.Type == lexer.USE {
+		return p.parseImport(leftToken)
+	} else if leftToken.Type == lexer.FROM {
+		return p.parseFrom(leftToken)
+	} else if leftToken.Type == lexer.PUB {
+		return p.parsePub(leftToken)
 	}
+	p.pos--
 	return nil, p.error("expected atom")
 }
 
+// parseNegativeNumber reads `-128i8` as a single literal, so that its range is checked with the sign
+func (p *Parser) parseNegativeNumber(minus lexer.Token) (ast.Expr, bool, error) {
+	next, err := p.Peek()
+	if err != nil || minus.Type != lexer.MINUS || next.Type != lexer.NUMBER_LITERAL || next.Value.Start != minus.Value.Start+1 {
+		return nil, false, nil
+	}
+	p.Consume()
+	source := utils.Encompass(minus.Value, next.Value)
+	value, err := env.NumberLiteral(source.String(), source)
+	if err != nil {
+		return nil, true, err
+	}
+	return ast.NewLiteral(value), true, nil
+}
+
+// parsePostfix applies calls, indexing and field accesses to an already parsed atom
+func (p *Parser) parsePostfix(atom ast.Expr) (ast.Expr, error) {
+	for {
+		next, err := p.Peek()
+		if err != nil {
+			return atom, nil
+		}
+		switch next.Type {
+		case lexer.L_PAREN:
+			atom, err = p.parseCall(atom)
+			if err != nil {
+				return nil, err
+			}
+		case lexer.L_BRACKET:
+			atom, err = p.parseIndex(atom)
+			if err != nil {
+				return nil, err
+			}
+		case lexer.PERIOD:
+			atom, err = p.parseField(atom)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return atom, nil
+		}
+	}
+}
+
+// assumes that the opening bracket has already been consumed
+func (p *Parser) parseList(open lexer.Token) (ast.Expr, error) {
+	items := []ast.Expr{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACKET {
+			p.Consume()
+			return ast.NewListExpr(items, utils.Encompass(open.Value, tok.Value)), nil
+		}
+		if len(items) > 0 {
+			if tok.Type != lexer.COMMA {
+				return nil, p.error("expected , or ] in list")
+			}
+			p.Consume()
+			p.SkipNewlines()
+		}
+		item, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// assumes that the target has been parsed and the next token is the opening bracket
+func (p *Parser) parseIndex(target ast.Expr) (ast.Expr, error) {
+	p.Consume()
+	p.SkipNewlines()
+	index, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.SkipNewlines()
+	end, err := p.TryConsume(lexer.R_BRACKET)
+	if err != nil {
+		return nil, p.error("expected ] after index")
+	}
+	return ast.NewIndexExpr(target, index, utils.Encompass(target.Source(), end.Value)), nil
+}
+
+// assumes that the mut or let keyword has already been consumed
+func (p *Parser) parseDeclaration(keyword lexer.Token) (ast.Expr, error) {
+	if next, err := p.Peek(); err == nil && next.Type == lexer.L_PAREN {
+		return p.parseDestructuringDeclaration(keyword)
+	}
+	identifier, err := p.TryConsume(lexer.IDENTIFIER)
+	if err != nil {
+		return nil, p.error("expected variable name")
+	}
+	if next, err := p.Peek(); err == nil && next.Type == lexer.COLON {
+		return p.parseAnnotatedDeclaration(keyword, identifier)
+	}
+	op, err := p.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != lexer.COLON_ASSIGN && op.Type != lexer.ASSIGN {
+		return nil, p.error("expected := after variable name")
+	}
+	p.Consume()
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewDeclarationExpr(keyword, identifier, value), nil
+}
+
+// `mut s : str = 'Fizz'`, assumes that the name has been consumed and the next token is the colon,
+// keyword is the mut or let keyword, or the name itself for a plain `s : str = 'Fizz'`
+func (p *Parser) parseAnnotatedDeclaration(keyword lexer.Token, identifier lexer.Token) (ast.Expr, error) {
+	p.Consume()
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != lexer.COLON_ASSIGN && op.Type != lexer.ASSIGN {
+		return nil, p.error("expected = after the type of " + identifier.Value.String())
+	}
+	p.Consume()
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewDeclarationExpr(keyword, identifier, value).WithType(t), nil
+}
+
+// `mut (a, b) := value`, assumes that the mut or let keyword has already been consumed
+func (p *Parser) parseDestructuringDeclaration(keyword lexer.Token) (ast.Expr, error) {
+	pattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPatternNames(pattern, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	op, err := p.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != lexer.COLON_ASSIGN && op.Type != lexer.ASSIGN {
+		return nil, p.error("expected := after pattern")
+	}
+	p.Consume()
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewDestructureExpr(pattern, value, keyword.Type != lexer.MUT, utils.Encompass(keyword.Value, value.Source())), nil
+}
+
+// assumes that the opening brace has already been consumed
+func (p *Parser) parseBlock() (ast.Expr, error) {
+	scope, err := p.parseScope()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.TryConsume(lexer.R_BRACE)
+	if err != nil {
+		return nil, err
+	}
+	return &scope, nil
+}
+
 func (p *Parser) parseExprWithPrecedence(minPrecedence int) (ast.Expr, error) {
 	left, err := p.parseAtom()
 	if err != nil {
 		return nil, err
 	}
+	// casts bind tighter than every binary operator but looser than unary ones, `-x as u8`
+	left, err = p.parseCasts(left)
+	if err != nil {
+		return nil, err
+	}
 
 	var currentToken lexer.Token
 	var currentPrecedence int
 	for p.pos < len(p.tokens) {
 
-		currentToken, err = p.TryConsumeKind(lexer.S_BINARY_OPERATOR)
-		if err != nil {
+		currentToken, err = p.Peek()
+		if err == nil && currentToken.Type == lexer.IS && operatorPrecedence[lexer.IS] >= minPrecedence {
+			left, err = p.parseIsNone(left)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil || !lexer.S_BINARY_OPERATOR.Matches(currentToken.Type) {
 			return left, nil
 		}
 		currentPrecedence = operatorPrecedence[currentToken.Type]
 		if currentPrecedence < minPrecedence {
 			return left, nil
 		}
+		p.Consume()
 		nextPrecedence := currentPrecedence + 1
+		if currentToken.Type == lexer.POWER {
+			// `2 ** 3 ** 2` is `2 ** (3 ** 2)`
+			nextPrecedence = currentPrecedence
+		}
 		right, err := p.parseExprWithPrecedence(nextPrecedence)
 		if err != nil {
 			return nil, err
 		}
-		leftCopy := new(ast.Expr)
-		*leftCopy = left
-		bin := ast.BinaryExpr{
-			Op:    currentToken.Type,
-			Left:  leftCopy,
-			Right: &right,
-		}
+		bin := ast.NewBinaryExpr(currentToken.Type, left, right)
 
 		left = &bin
 	}
@@ -121,6 +387,18 @@ func (p *Parser) parseExprWithPrecedence(minPrecedence int) (ast.Expr, error) {
 	return left, nil
 }
 
+// parseIsNone parses `is none` or `is not none` after value
+func (p *Parser) parseIsNone(value ast.Expr) (ast.Expr, error) {
+	p.Consume()
+	_, err := p.TryConsume(lexer.NOT)
+	negated := err == nil
+	end, err := p.TryConsume(lexer.NONE)
+	if err != nil {
+		return nil, p.error("expected none after is")
+	}
+	return ast.NewIsNoneExpr(value, negated, utils.Encompass(value.Source(), end.Value)), nil
+}
+
 // assumes that the if token has already been consumed
 func (p *Parser) parseIfExpr() (ast.Expr, error) {
 	condition, err := p.ParseExpr()
@@ -132,13 +410,16 @@ func (p *Parser) parseIfExpr() (ast.Expr, error) {
 	if err != nil {
 		return ast.ConditionalExpr{}, err
 	}
-	thenExpr, err := p.Parse()
+	thenExpr, err := p.parseScope()
 	if err != nil {
 		return ast.ConditionalExpr{}, err
 	}
 	_, err = p.TryConsume(lexer.R_BRACE)
+	if err != nil {
+		return ast.ConditionalExpr{}, err
+	}
 
-	next, err := p.Peek()
+	next, err := p.PeekPastNewlines()
 	if err != nil {
 		return ast.ConditionalExpr{
 			Condition: condition,
@@ -148,12 +429,13 @@ func (p *Parser) parseIfExpr() (ast.Expr, error) {
 	}
 
 	if next.Type == lexer.ELIF {
+		p.SkipNewlines()
 		p.Consume()
 		next, err := p.parseIfExpr()
-		nextCond := next.(ast.ConditionalExpr)
 		if err != nil {
 			return ast.ConditionalExpr{}, err
 		}
+		nextCond := next.(ast.ConditionalExpr)
 		return ast.ConditionalExpr{
 			Condition: condition,
 			Content:   thenExpr,
@@ -162,8 +444,13 @@ func (p *Parser) parseIfExpr() (ast.Expr, error) {
 	}
 
 	if next.Type == lexer.ELSE {
-		p.ConsumeTokens(2) // consume the else and the brace
-		nextScope, err := p.Parse()
+		p.SkipNewlines()
+		p.Consume()
+		_, err = p.TryConsume(lexer.L_BRACE)
+		if err != nil {
+			return ast.ConditionalExpr{}, err
+		}
+		nextScope, err := p.parseScope()
 		if err != nil {
 			return ast.ConditionalExpr{}, err
 		}
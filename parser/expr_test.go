@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"testing"
+
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/lexer"
+)
+
+func TestOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		source      string
+		op          lexer.TokenType // the operator applied last
+		left, right string
+	}{
+		{"x in 0..10 and y in 0..10", lexer.AND, "x in 0..10", "y in 0..10"},
+		{"x is none and y is none", lexer.AND, "x is none", "y is none"},
+		{"x is none or y is not none", lexer.OR, "x is none", "y is not none"},
+		{"a == 1 or b == 2 and c", lexer.OR, "a == 1", "b == 2 and c"},
+		{"a < 1 and b >= 2", lexer.AND, "a < 1", "b >= 2"},
+		{"x in 0..n + 1", lexer.IN, "x", "0..n + 1"},
+		{"1 + 2 == 3", lexer.EQUAL, "1 + 2", "3"},
+		{"a & b == c", lexer.EQUAL, "a & b", "c"},
+	}
+	for _, test := range tests {
+		p, err := NewParser(test.source)
+		if err != nil {
+			t.Fatalf("lexing %q failed: %v", test.source, err)
+		}
+		expr, err := p.ParseExpr()
+		if err != nil {
+			t.Errorf("parsing %q failed: %v", test.source, err)
+			continue
+		}
+		bin, ok := expr.(*ast.BinaryExpr)
+		if !ok {
+			t.Errorf("%q parses as %T, want a binary expression", test.source, expr)
+			continue
+		}
+		left, right := (*bin.Left).Source().String(), (*bin.Right).Source().String()
+		if bin.Op != test.op || left != test.left || right != test.right {
+			t.Errorf("%q parses as (%s) %v (%s), want (%s) %v (%s)", test.source, left, bin.Op, right, test.left, test.op, test.right)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// assumes that the fn token has already been consumed
+//
+//	fn name(a: type, b: type) -> expr
+//	fn name(a: type): type { ... }
+//	fn (x) -> x * 2
+//
+// An anonymous function is a closure over the scope it is evaluated in, its parameters may
+// leave out their types.
+func (p *Parser) parseFunctionDecl(fnToken lexer.Token) (ast.Expr, error) {
+	name := ""
+	if next, err := p.Peek(); err != nil || next.Type != lexer.L_PAREN {
+		nameToken, err := p.TryConsume(lexer.IDENTIFIER)
+		if err != nil {
+			return nil, p.error("expected function name or (")
+		}
+		name = nameToken.Value.String()
+	}
+	var typeParams []env.TypeParam
+	if next, err := p.Peek(); err == nil && next.Type == lexer.LESS_THAN {
+		typeParams, err = p.parseTypeParams()
+		if err != nil {
+			return nil, err
+		}
+	}
+	params, err := p.parseParams(name == "")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret env.Type
+	next, err := p.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if next.Type == lexer.COLON {
+		p.Consume()
+		ret, err = p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		next, err = p.Peek()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var body ast.Expr
+	switch next.Type {
+	case lexer.MAP_ARROW:
+		p.Consume()
+		p.SkipNewlines()
+		body, err = p.ParseExpr()
+	case lexer.L_BRACE:
+		p.Consume()
+		body, err = p.parseBlock()
+	default:
+		return nil, p.error("expected -> or { after function signature")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := utils.Encompass(fnToken.Value, body.Source())
+	return ast.NewFunctionDecl(name, typeParams, params, ret, body, source), nil
+}
+
+// parseTypeParams parses the type parameters of a generic function and their bounds
+//
+//	<T, U implements Addition + Ordering>
+func (p *Parser) parseTypeParams() ([]env.TypeParam, error) {
+	p.Consume()
+	params := []env.TypeParam{}
+	for {
+		name, err := p.TryConsume(lexer.GENERIC)
+		if err != nil {
+			return nil, p.error("expected a type parameter, a single capital letter such as T")
+		}
+		for _, param := range params {
+			if param.Name == name.Value.String() {
+				return nil, name.Error("duplicate type parameter")
+			}
+		}
+		param := env.TypeParam{Name: name.Value.String()}
+		if _, err := p.TryConsume(lexer.IMPLEMENTS); err == nil {
+			for {
+				bound, err := p.TryConsume(lexer.USER_DEFINED)
+				if err != nil {
+					return nil, p.error("expected a trait after implements")
+				}
+				param.Bounds = append(param.Bounds, bound.Value.String())
+				if _, err := p.TryConsume(lexer.PLUS); err != nil {
+					break
+				}
+			}
+		}
+		params = append(params, param)
+		if _, err := p.TryConsume(lexer.GREATER_THAN); err == nil {
+			return params, nil
+		}
+		if _, err := p.TryConsume(lexer.COMMA); err != nil {
+			return nil, p.error("expected , or > in type parameters")
+		}
+	}
+}
+
+// parseParams parses the parameters of a function, positional ones and groups of named ones.
+// The types of untyped parameters are inferred from their arguments.
+//
+//	(a: i32, b: i32 = 2, {sep: str = ', ', end: str?})
+func (p *Parser) parseParams(untyped bool) ([]env.Param, error) {
+	_, err := p.TryConsume(lexer.L_PAREN)
+	if err != nil {
+		return nil, err
+	}
+	params := []env.Param{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_PAREN {
+			p.Consume()
+			return params, nil
+		}
+		if len(params) > 0 {
+			if tok.Type != lexer.COMMA {
+				return nil, p.error("expected , or ) in parameter list")
+			}
+			p.Consume()
+			p.SkipNewlines()
+		}
+
+		if _, err := p.TryConsume(lexer.L_BRACE); err == nil {
+			params, err = p.parseNamedParams(params, untyped)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// the receiver of a method takes the implementing type
+		if p.isSelf() {
+			if len(params) > 0 {
+				return nil, p.error("self must be the first parameter")
+			}
+			_, err := p.TryConsume(lexer.MUT)
+			self, _ := p.Consume()
+			params = append(params, env.Param{Name: self.Value.String(), Type: env.Self, Mutable: err == nil})
+			continue
+		}
+
+		start, _ := p.Peek()
+		param, err := p.parseParam(params, false, untyped)
+		if err != nil {
+			return nil, err
+		}
+		if param.Default == nil {
+			for _, previous := range params {
+				if !previous.Named && previous.Default != nil {
+					return nil, start.Error("a positional parameter without default cannot follow one with a default")
+				}
+			}
+		}
+		params = append(params, param)
+	}
+}
+
+// isSelf reports whether the next parameter is the receiver of a method, `self` or `mut self`
+func (p *Parser) isSelf() bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].Type == lexer.MUT {
+		return p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].Type == lexer.SELF
+	}
+	return p.pos < len(p.tokens) && p.tokens[p.pos].Type == lexer.SELF
+}
+
+// parseNamedParams parses a group of named parameters after its opening brace and appends them
+// to params
+//
+//	{sep: str = ', ', end: str?}
+func (p *Parser) parseNamedParams(params []env.Param, untyped bool) ([]env.Param, error) {
+	first := true
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACE {
+			p.Consume()
+			return params, nil
+		}
+		if !first {
+			if tok.Type != lexer.COMMA {
+				return nil, p.error("expected , or } in named parameters")
+			}
+			p.Consume()
+			p.SkipNewlines()
+		}
+		first = false
+		param, err := p.parseParam(params, true, untyped)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+	}
+}
+
+// parseParam parses a single parameter and its default value, its type is optional when untyped
+//
+//	mut name: type = default
+func (p *Parser) parseParam(params []env.Param, named, untyped bool) (env.Param, error) {
+	mutable := false
+	if _, err := p.TryConsume(lexer.MUT); err == nil {
+		mutable = true
+	}
+	name, err := p.TryConsume(lexer.IDENTIFIER)
+	if err != nil {
+		return env.Param{}, p.error("expected parameter name")
+	}
+	for _, param := range params {
+		if param.Name == name.Value.String() {
+			return env.Param{}, name.Error("duplicate parameter name")
+		}
+	}
+	var t env.Type
+	if _, err := p.TryConsume(lexer.COLON); err == nil {
+		t, err = p.parseType()
+		if err != nil {
+			return env.Param{}, err
+		}
+	} else if !untyped {
+		return env.Param{}, p.error("expected : and parameter type")
+	}
+	param := env.Param{Name: name.Value.String(), Type: t, Mutable: mutable, Named: named}
+	if _, err := p.TryConsume(lexer.ASSIGN); err == nil {
+		p.SkipNewlines()
+		value, err := p.ParseExpr()
+		if err != nil {
+			return env.Param{}, err
+		}
+		param.Default = value
+	}
+	return param, nil
+}
+
+// assumes that the callee has been parsed and the next token is the opening parenthesis
+//
+//	callee(a, b)
+//	Person(name: "Anas", age: 22)
+func (p *Parser) parseCall(callee ast.Expr) (ast.Expr, error) {
+	p.Consume()
+	args := []ast.CallArg{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_PAREN {
+			p.Consume()
+			source := utils.Encompass(callee.Source(), tok.Value)
+			return ast.NewCallExpr(callee, args, source), nil
+		}
+		if len(args) > 0 {
+			if tok.Type != lexer.COMMA {
+				return nil, p.error("expected , or ) in argument list")
+			}
+			p.Consume()
+			p.SkipNewlines()
+		}
+		arg, err := p.parseCallArg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+}
+
+// parseCallArg parses a positional argument or a named one, `name: value`
+func (p *Parser) parseCallArg() (ast.CallArg, error) {
+	if p.pos+1 < len(p.tokens) && p.tokens[p.pos].Type == lexer.IDENTIFIER && p.tokens[p.pos+1].Type == lexer.COLON {
+		name := p.tokens[p.pos]
+		p.ConsumeTokens(2)
+		p.SkipNewlines()
+		value, err := p.ParseExpr()
+		if err != nil {
+			return ast.CallArg{}, err
+		}
+		return ast.NewCallArg(name.Value.String(), value, utils.Encompass(name.Value, value.Source())), nil
+	}
+	value, err := p.ParseExpr()
+	if err != nil {
+		return ast.CallArg{}, err
+	}
+	return ast.NewCallArg("", value, value.Source()), nil
+}
+
+// assumes that the ret token has already been consumed
+func (p *Parser) parseReturn(retToken lexer.Token) (ast.Expr, error) {
+	next, err := p.Peek()
+	if err != nil || next.Type == lexer.NEWLINE || next.Type == lexer.R_BRACE {
+		return ast.NewReturnExpr(nil, retToken.Value), nil
+	}
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewReturnExpr(value, utils.Encompass(retToken.Value, value.Source())), nil
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// parseLoopBody parses the braces delimited body of a loop
+func (p *Parser) parseLoopBody() (ast.Scope, lexer.Token, error) {
+	_, err := p.TryConsume(lexer.L_BRACE)
+	if err != nil {
+		return ast.Scope{}, lexer.Token{}, p.error("expected { to start loop body")
+	}
+	body, err := p.parseScope()
+	if err != nil {
+		return ast.Scope{}, lexer.Token{}, err
+	}
+	end, err := p.TryConsume(lexer.R_BRACE)
+	if err != nil {
+		return ast.Scope{}, lexer.Token{}, err
+	}
+	return body, end, nil
+}
+
+// assumes that the while token has already been consumed
+func (p *Parser) parseWhile(whileToken lexer.Token) (ast.Expr, error) {
+	condition, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	body, end, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewWhileExpr(condition, body, utils.Encompass(whileToken.Value, end.Value)), nil
+}
+
+// assumes that the loop token has already been consumed
+func (p *Parser) parseLoop(loopToken lexer.Token) (ast.Expr, error) {
+	body, end, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewLoopExpr(body, utils.Encompass(loopToken.Value, end.Value)), nil
+}
+
+// assumes that the for token has already been consumed
+func (p *Parser) parseFor(forToken lexer.Token) (ast.Expr, error) {
+	pattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPatternNames(pattern, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	_, err = p.TryConsume(lexer.IN)
+	if err != nil {
+		return nil, p.error("expected in after loop variable")
+	}
+	iterable, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	body, end, err := p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewForExpr(pattern, iterable, body, utils.Encompass(forToken.Value, end.Value)), nil
+}
+
+// assumes that the break token has already been consumed
+func (p *Parser) parseBreak(breakToken lexer.Token) (ast.Expr, error) {
+	next, err := p.Peek()
+	if err != nil || next.Type == lexer.NEWLINE || next.Type == lexer.R_BRACE {
+		return ast.NewBreakExpr(nil, breakToken.Value), nil
+	}
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewBreakExpr(value, utils.Encompass(breakToken.Value, value.Source())), nil
+}
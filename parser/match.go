@@ -0,0 +1,219 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// assumes that the enum token has already been consumed
+//
+//	enum Color {
+//	    (u8, u8, u8)
+//	    Black
+//	    Rgb(u8, u8, u8)
+//	}
+func (p *Parser) parseEnum(enumToken lexer.Token) (ast.Expr, error) {
+	name, err := p.Consume()
+	if err != nil {
+		return nil, err
+	}
+	if name.Type != lexer.USER_DEFINED && name.Type != lexer.GENERIC {
+		p.pos--
+		return nil, p.error("expected a capitalized enum name")
+	}
+	_, err = p.TryConsume(lexer.L_BRACE)
+	if err != nil {
+		return nil, p.error("expected { after enum name")
+	}
+
+	variants := []env.Variant{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACE {
+			p.Consume()
+			return ast.NewEnumDecl(name.Value.String(), variants, utils.Encompass(enumToken.Value, tok.Value)), nil
+		}
+		if tok.Type == lexer.COMMA && len(variants) > 0 {
+			p.Consume()
+			continue
+		}
+
+		variant := env.Variant{}
+		if tok.Type == lexer.USER_DEFINED || tok.Type == lexer.GENERIC {
+			p.Consume()
+			variant.Name = tok.Value.String()
+		} else if tok.Type != lexer.L_PAREN {
+			return nil, p.error("expected a capitalized variant name or a tuple of types")
+		}
+		if next, err := p.Peek(); err == nil && next.Type == lexer.L_PAREN {
+			payload, err := p.parseTupleType()
+			if err != nil {
+				return nil, err
+			}
+			variant.Payload = payload.(env.TupleType).Elems
+			if len(variant.Payload) == 0 {
+				return nil, utils.Error{Source: utils.Encompass(tok.Value, p.tokens[p.pos-1].Value), Message: "variant payload cannot be empty"}
+			}
+		}
+		for _, other := range variants {
+			if variant.Name != "" && other.Name == variant.Name {
+				return nil, tok.Error("duplicate variant name")
+			}
+			if variant.Name == "" && other.Name == "" && env.SameType(env.TupleType{Elems: other.Payload}, env.TupleType{Elems: variant.Payload}) {
+				return nil, utils.Error{Source: utils.Encompass(tok.Value, p.tokens[p.pos-1].Value), Message: "duplicate anonymous variant"}
+			}
+		}
+		variants = append(variants, variant)
+	}
+}
+
+// assumes that the match token has already been consumed
+//
+//	match color {
+//	    (r: u8, g: u8, b: u8) => (r, g, b)
+//	    Black => (0, 0, 0)
+//	    _ => (255, 255, 255)
+//	}
+func (p *Parser) parseMatch(matchToken lexer.Token) (ast.Expr, error) {
+	scrutinee, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.TryConsume(lexer.L_BRACE)
+	if err != nil {
+		return nil, p.error("expected { after match expression")
+	}
+
+	arms := []ast.MatchArm{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACE {
+			p.Consume()
+			if len(arms) == 0 {
+				return nil, tok.Error("match needs at least one arm")
+			}
+			return ast.NewMatchExpr(scrutinee, arms, utils.Encompass(matchToken.Value, tok.Value)), nil
+		}
+		if tok.Type == lexer.COMMA && len(arms) > 0 {
+			p.Consume()
+			continue
+		}
+
+		pattern, err := p.parseMatchPattern()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.TryConsume(lexer.MATCH_ARROW)
+		if err != nil {
+			return nil, p.error("expected => after pattern")
+		}
+		body, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		arms = append(arms, ast.MatchArm{Pattern: pattern, Body: body})
+	}
+}
+
+// parseMatchPattern parses `_`, `name[: type]`, `[Enum.]Variant[(patterns)]`,
+// `(patterns)` or a literal
+func (p *Parser) parseMatchPattern() (ast.MatchPattern, error) {
+	tok, err := p.Consume()
+	if err != nil {
+		return ast.MatchPattern{}, err
+	}
+	switch tok.Type {
+	case lexer.IDENTIFIER:
+		if tok.Value.String() == "_" {
+			return ast.NewWildcardPattern(tok.Value), nil
+		}
+		if next, err := p.Peek(); err == nil && next.Type == lexer.COLON {
+			p.Consume()
+			t, err := p.parseType()
+			if err != nil {
+				return ast.MatchPattern{}, err
+			}
+			return ast.NewBindingPattern(tok.Value.String(), t, utils.Encompass(tok.Value, p.tokens[p.pos-1].Value)), nil
+		}
+		return ast.NewBindingPattern(tok.Value.String(), nil, tok.Value), nil
+	case lexer.USER_DEFINED, lexer.GENERIC:
+		enum, name := "", tok
+		if next, err := p.Peek(); err == nil && next.Type == lexer.PERIOD {
+			p.Consume()
+			name, err = p.Consume()
+			if err != nil {
+				return ast.MatchPattern{}, err
+			}
+			if name.Type != lexer.USER_DEFINED && name.Type != lexer.GENERIC {
+				p.pos--
+				return ast.MatchPattern{}, p.error("expected a variant name after .")
+			}
+			enum = tok.Value.String()
+		}
+		if next, err := p.Peek(); err == nil && next.Type == lexer.L_PAREN {
+			elems, end, err := p.parseMatchPatternList()
+			if err != nil {
+				return ast.MatchPattern{}, err
+			}
+			return ast.NewVariantPattern(enum, name.Value.String(), elems, utils.Encompass(tok.Value, end.Value)), nil
+		}
+		return ast.NewVariantPattern(enum, name.Value.String(), nil, utils.Encompass(tok.Value, name.Value)), nil
+	case lexer.L_PAREN:
+		p.pos--
+		elems, end, err := p.parseMatchPatternList()
+		if err != nil {
+			return ast.MatchPattern{}, err
+		}
+		return ast.NewTupleMatchPattern(elems, utils.Encompass(tok.Value, end.Value)), nil
+	case lexer.NUMBER_LITERAL, lexer.STRING_LITERAL, lexer.TRUE, lexer.FALSE, lexer.NONE:
+		lit, err := ast.LiteralFromToken(tok)
+		if err != nil {
+			return ast.MatchPattern{}, err
+		}
+		return ast.NewLiteralPattern(lit.Value, tok.Value), nil
+	}
+	p.pos--
+	return ast.MatchPattern{}, p.error("expected a pattern")
+}
+
+// parseMatchPatternList parses `(pattern, pattern, ...)` and returns the closing parenthesis
+func (p *Parser) parseMatchPatternList() ([]ast.MatchPattern, lexer.Token, error) {
+	p.Consume()
+	elems := []ast.MatchPattern{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, lexer.Token{}, err
+		}
+		if tok.Type == lexer.R_PAREN {
+			p.Consume()
+			if len(elems) == 0 {
+				return nil, lexer.Token{}, tok.Error("expected a pattern")
+			}
+			return elems, tok, nil
+		}
+		if len(elems) > 0 {
+			if tok.Type != lexer.COMMA {
+				return nil, lexer.Token{}, p.error("expected , or ) in pattern")
+			}
+			p.Consume()
+			p.SkipNewlines()
+		}
+		elem, err := p.parseMatchPattern()
+		if err != nil {
+			return nil, lexer.Token{}, err
+		}
+		elems = append(elems, elem)
+	}
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// assumes that the mod token has already been consumed
+//
+//	mod geometry
+func (p *Parser) parseMod(modToken lexer.Token) (ast.Expr, error) {
+	name, err := p.TryConsume(lexer.IDENTIFIER)
+	if err != nil {
+		return nil, p.error("expected a module name")
+	}
+	return ast.NewModDecl(name.Value.String(), utils.Encompass(modToken.Value, name.Value)), nil
+}
+
+// assumes that the import or use token has already been consumed
+//
+//	import geometry.shapes
+//	import geometry.shapes as sh
+//	use shapes
+func (p *Parser) parseImport(importToken lexer.Token) (ast.Expr, error) {
+	path, end, err := p.parseModulePath()
+	if err != nil {
+		return nil, err
+	}
+	alias := path[len(path)-1]
+	if _, err := p.TryConsume(lexer.AS); err == nil {
+		name, err := p.TryConsume(lexer.IDENTIFIER)
+		if err != nil {
+			return nil, p.error("expected a name after as")
+		}
+		alias, end = name.Value.String(), name.Value
+	}
+	source := utils.Encompass(importToken.Value, end)
+	return ast.NewImportExpr(path, alias, importToken.Type == lexer.USE, nil, source), nil
+}
+
+// assumes that the from token has already been consumed
+//
+//	from geometry.shapes import Square, area as square_area
+func (p *Parser) parseFrom(fromToken lexer.Token) (ast.Expr, error) {
+	path, _, err := p.parseModulePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.TryConsume(lexer.IMPORT); err != nil {
+		return nil, p.error("expected import after the module path")
+	}
+	names := []ast.ImportName{}
+	for {
+		name, err := p.consumeImportedName()
+		if err != nil {
+			return nil, err
+		}
+		alias, end := name.Value.String(), name.Value
+		if _, err := p.TryConsume(lexer.AS); err == nil {
+			aliasToken, err := p.consumeImportedName()
+			if err != nil {
+				return nil, err
+			}
+			alias, end = aliasToken.Value.String(), aliasToken.Value
+		}
+		for _, imported := range names {
+			if imported.Alias == alias {
+				return nil, utils.Error{Source: end, Message: "duplicate imported name"}
+			}
+		}
+		names = append(names, ast.NewImportName(name.Value.String(), alias, utils.Encompass(name.Value, end)))
+		if _, err := p.TryConsume(lexer.COMMA); err != nil {
+			source := utils.Encompass(fromToken.Value, end)
+			return ast.NewImportExpr(path, "", false, names, source), nil
+		}
+	}
+}
+
+// parseModulePath parses the dotted path of a module, `geometry.shapes`, and returns its last token
+func (p *Parser) parseModulePath() ([]string, utils.String, error) {
+	path := []string{}
+	for {
+		segment, err := p.TryConsume(lexer.IDENTIFIER)
+		if err != nil {
+			return nil, utils.String{}, p.error("expected a module name")
+		}
+		path = append(path, segment.Value.String())
+		if _, err := p.TryConsume(lexer.PERIOD); err != nil {
+			return path, segment.Value, nil
+		}
+	}
+}
+
+// consumeImportedName consumes the name of a declaration imported by `from`, types are capitalized
+func (p *Parser) consumeImportedName() (lexer.Token, error) {
+	tok, err := p.Consume()
+	if err != nil {
+		return lexer.Token{}, err
+	}
+	if tok.Type != lexer.IDENTIFIER && tok.Type != lexer.USER_DEFINED && tok.Type != lexer.GENERIC {
+		p.pos--
+		return lexer.Token{}, p.error("expected a name to import")
+	}
+	return tok, nil
+}
+
+// assumes that the pub token has already been consumed
+//
+//	pub fn area(s: Square) : f32 -> s.side * s.side
+//	pub comp Square { side: f32 }
+//	pub unit := 1.0
+func (p *Parser) parsePub(pubToken lexer.Token) (ast.Expr, error) {
+	decl, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ast.DeclaredName(decl); !ok {
+		return nil, utils.Error{Source: decl.Source(), Message: "pub must be followed by a named declaration"}
+	}
+	return ast.NewPubDecl(decl, utils.Encompass(pubToken.Value, decl.Source())), nil
+}
@@ -12,6 +12,9 @@ type Parser struct {
 }
 
 func (p *Parser) error(message string) error {
+	if len(p.tokens) == 0 {
+		return utils.Error{Message: message}
+	}
 	if p.pos >= len(p.tokens) {
 		return utils.Error{Source: p.tokens[len(p.tokens)-1].Value, Message: message}
 	}
@@ -19,8 +22,16 @@ func (p *Parser) error(message string) error {
 }
 
 func NewParser(source string) (*Parser, error) {
-	lexer := lexer.NewLexer(source)
-	tokens, err := lexer.Tokenize()
+	return newParser(lexer.NewLexer(source))
+}
+
+// NewFileParser parses source read from file, the errors found in it show the file
+func NewFileParser(source, file string) (*Parser, error) {
+	return newParser(lexer.NewFileLexer(source, file))
+}
+
+func newParser(l *lexer.Lexer) (*Parser, error) {
+	tokens, err := l.Tokenize()
 	if err != nil {
 		return nil, err
 	}
@@ -73,39 +84,59 @@ func (p *Parser) TryConsumeKind(kind lexer.ShapeType) (lexer.Token, error) {
 func (p *Parser) Consume() (lexer.Token, error) {
 	consumedToken, err := p.Peek()
 	if err != nil {
-		panic(err)
+		return lexer.Token{}, err
 	}
 	p.pos++
 	return consumedToken, nil
 }
 
+// SkipNewlines consumes newlines until the next meaningful token
+func (p *Parser) SkipNewlines() {
+	for p.pos < len(p.tokens) && p.tokens[p.pos].Type == lexer.NEWLINE {
+		p.pos++
+	}
+}
+
+// PeekPastNewlines returns the next token that is not a newline without consuming anything
+func (p *Parser) PeekPastNewlines() (lexer.Token, error) {
+	pos := p.pos
+	p.SkipNewlines()
+	tok, err := p.Peek()
+	p.pos = pos
+	return tok, err
+}
+
+// Parse parses a whole program, every token has to be consumed
 func (p *Parser) Parse() (ast.Scope, error) {
+	program, err := p.parseScope()
+	if err != nil {
+		return program, err
+	}
+	if p.pos < len(p.tokens) {
+		return program, p.error("unexpected token")
+	}
+	return program, nil
+}
+
+// parseScope parses newline separated expressions until EOF or a closing brace,
+// which is left for the caller to consume
+func (p *Parser) parseScope() (ast.Scope, error) {
 	program := ast.Scope{}
-	for p.pos < len(p.tokens) {
+	for {
+		p.SkipNewlines()
 		tok, err := p.Peek()
-		if err != nil {
+		if err != nil || tok.Type == lexer.R_BRACE {
 			return program, nil
 		}
-		if lexer.S_UNARY_OPERATOR.Matches(tok.Type) || lexer.S_VALUE.Matches(tok.Type) || tok.Type == lexer.IF {
-			expr, err := p.ParseExpr()
-			if err != nil {
-				return program, err
-			}
-			program.Exprs = append(program.Exprs, expr)
-		} else if p.tokens[p.pos].Type == lexer.L_BRACE {
-			p.Consume()
-			scope, err := p.Parse()
-			if err != nil {
-				return program, err
-			}
-			_, err = p.TryConsume(lexer.R_BRACE)
-			if err != nil {
-				return program, err
-			}
-			program.Exprs = append(program.Exprs, &scope)
-		} else {
-			break
+		expr, err := p.ParseExpr()
+		if err != nil {
+			return program, err
+		}
+		program.Exprs = append(program.Exprs, expr)
+
+		next, err := p.Peek()
+		if err == nil && next.Type != lexer.NEWLINE && next.Type != lexer.R_BRACE {
+			return program, p.error("expected a newline after expression")
 		}
 	}
-	return program, nil
 }
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// parseInterpolation parses a string with `{expr}` parts, head is its text up to the first `{`
+func (p *Parser) parseInterpolation(head lexer.Token) (ast.Expr, error) {
+	parts := []ast.Expr{}
+	tok := head
+	for {
+		text, err := ast.LiteralFromToken(tok)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, text)
+		if tok.Type == lexer.STRING_TAIL {
+			return ast.NewInterpolatedString(parts, utils.Encompass(head.Value, tok.Value)), nil
+		}
+		expr, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, expr)
+		if tok, err = p.Peek(); err != nil || (tok.Type != lexer.STRING_MIDDLE && tok.Type != lexer.STRING_TAIL) {
+			return nil, p.error("expected } after the interpolated expression")
+		}
+		p.Consume()
+	}
+}
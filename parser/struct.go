@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// assumes that the comp token has already been consumed
+//
+//	comp Person {
+//	    name: str
+//	    age: u8
+//	}
+func (p *Parser) parseComp(compToken lexer.Token) (ast.Expr, error) {
+	name, err := p.Consume()
+	if err != nil {
+		return nil, err
+	}
+	if name.Type != lexer.USER_DEFINED && name.Type != lexer.GENERIC {
+		p.pos--
+		return nil, p.error("expected a capitalized comp name")
+	}
+	_, err = p.TryConsume(lexer.L_BRACE)
+	if err != nil {
+		return nil, p.error("expected { after comp name")
+	}
+
+	fields := []env.Field{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACE {
+			p.Consume()
+			return ast.NewCompDecl(name.Value.String(), fields, utils.Encompass(compToken.Value, tok.Value)), nil
+		}
+		if tok.Type == lexer.COMMA && len(fields) > 0 {
+			p.Consume()
+			continue
+		}
+
+		fieldName, err := p.TryConsume(lexer.IDENTIFIER)
+		if err != nil {
+			return nil, p.error("expected field name")
+		}
+		for _, field := range fields {
+			if field.Name == fieldName.Value.String() {
+				return nil, fieldName.Error("duplicate field name")
+			}
+		}
+		_, err = p.TryConsume(lexer.COLON)
+		if err != nil {
+			return nil, p.error("expected : and field type")
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, env.Field{Name: fieldName.Value.String(), Type: t})
+	}
+}
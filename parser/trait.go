@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// assumes that the abs token has already been consumed
+//
+//	abs Shape {
+//	    fn area(self) : f32
+//	    unit : Self
+//	}
+func (p *Parser) parseAbs(absToken lexer.Token) (ast.Expr, error) {
+	name, err := p.Consume()
+	if err != nil {
+		return nil, err
+	}
+	if name.Type != lexer.USER_DEFINED && name.Type != lexer.GENERIC {
+		p.pos--
+		return nil, p.error("expected a capitalized trait name")
+	}
+	_, err = p.TryConsume(lexer.L_BRACE)
+	if err != nil {
+		return nil, p.error("expected { after trait name")
+	}
+
+	methods, constants := []env.Field{}, []env.Field{}
+	declared := map[string]bool{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACE {
+			p.Consume()
+			return ast.NewAbsDecl(name.Value.String(), methods, constants, utils.Encompass(absToken.Value, tok.Value)), nil
+		}
+
+		isMethod := tok.Type == lexer.FN
+		if isMethod {
+			p.Consume()
+		}
+		memberName, err := p.TryConsume(lexer.IDENTIFIER)
+		if err != nil {
+			if isMethod {
+				return nil, p.error("expected method name")
+			}
+			return nil, p.error("expected a method or a constant")
+		}
+		if declared[memberName.Value.String()] {
+			return nil, memberName.Error("duplicate method or constant name")
+		}
+		declared[memberName.Value.String()] = true
+
+		if !isMethod {
+			_, err = p.TryConsume(lexer.COLON)
+			if err != nil {
+				return nil, p.error("expected : and constant type")
+			}
+			t, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			constants = append(constants, env.Field{Name: memberName.Value.String(), Type: t})
+			continue
+		}
+		params, err := p.parseParams(false)
+		if err != nil {
+			return nil, err
+		}
+		var ret env.Type
+		if _, err := p.TryConsume(lexer.COLON); err == nil {
+			ret, err = p.parseType()
+			if err != nil {
+				return nil, err
+			}
+		}
+		method := env.NewFunctionType(nil, params, ret)
+		methods = append(methods, env.Field{Name: memberName.Value.String(), Type: method})
+	}
+}
+
+// assumes that the impl token has already been consumed
+//
+//	impl Addition for Vec {
+//	    zero := Vec(x: 0, y: 0)
+//	    fn add(self, other: Self) : Self -> Vec(x: self.x + other.x, y: self.y + other.y)
+//	}
+func (p *Parser) parseImpl(implToken lexer.Token) (ast.Expr, error) {
+	trait, err := p.Consume()
+	if err != nil {
+		return nil, err
+	}
+	if trait.Type != lexer.USER_DEFINED && trait.Type != lexer.GENERIC {
+		p.pos--
+		return nil, p.error("expected a trait after impl")
+	}
+	_, err = p.TryConsume(lexer.FOR)
+	if err != nil {
+		return nil, p.error("expected for and the implementing type")
+	}
+	forType, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.TryConsume(lexer.L_BRACE)
+	if err != nil {
+		return nil, p.error("expected { after the implementing type")
+	}
+
+	methods, constants := []ast.FunctionDecl{}, []ast.AssignmentExpr{}
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_BRACE {
+			p.Consume()
+			source := utils.Encompass(implToken.Value, tok.Value)
+			return ast.NewImplDecl(trait.Value.String(), forType, methods, constants, source), nil
+		}
+		expr, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		switch member := expr.(type) {
+		case ast.FunctionDecl:
+			methods = append(methods, member)
+		case ast.AssignmentExpr:
+			if member.Kind != ast.DECLARATION {
+				return nil, utils.Error{Source: member.Source(), Message: "constants of an impl are declared with :="}
+			}
+			constants = append(constants, member)
+		default:
+			return nil, utils.Error{Source: expr.Source(), Message: "expected a method or a constant"}
+		}
+	}
+}
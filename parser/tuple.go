@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"strings"
+
+	"com.loop.anonx3247/ast"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// assumes that the opening parenthesis has already been consumed, parses
+// a parenthesised expression, a tuple or a destructuring declaration
+func (p *Parser) parseParenthesised(open lexer.Token) (ast.Expr, error) {
+	start := p.pos - 1
+
+	// a tuple of names followed by := is a destructuring declaration
+	p.pos = start
+	if pattern, err := p.parsePattern(); err == nil {
+		if next, err := p.Peek(); err == nil && next.Type == lexer.COLON_ASSIGN {
+			if err := checkPatternNames(pattern, map[string]bool{}); err != nil {
+				return nil, err
+			}
+			p.Consume()
+			value, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return ast.NewDestructureExpr(pattern, value, true, utils.Encompass(pattern.Source(), value.Source())), nil
+		}
+	}
+	p.pos = start + 1
+
+	items := []ast.Expr{}
+	trailingComma := false
+	for {
+		p.SkipNewlines()
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_PAREN {
+			p.Consume()
+			if len(items) == 1 && !trailingComma {
+				return ast.ParenExpr{Expr: items[0]}, nil
+			}
+			return ast.NewTupleExpr(items, utils.Encompass(open.Value, tok.Value)), nil
+		}
+		if len(items) > 0 && !trailingComma {
+			return nil, p.error("expected , or )")
+		}
+		item, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.SkipNewlines()
+		next, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		trailingComma = next.Type == lexer.COMMA
+		if trailingComma {
+			p.Consume()
+		}
+	}
+}
+
+// parsePattern parses a name or a parenthesised tuple of patterns, `(r, (g, b))`
+func (p *Parser) parsePattern() (ast.Pattern, error) {
+	tok, err := p.Consume()
+	if err != nil {
+		return ast.Pattern{}, err
+	}
+	if tok.Type == lexer.IDENTIFIER {
+		return ast.NewNamePattern(tok.Value), nil
+	}
+	if tok.Type != lexer.L_PAREN {
+		p.pos--
+		return ast.Pattern{}, p.error("expected a name or a tuple of names")
+	}
+	elems := []ast.Pattern{}
+	for {
+		p.SkipNewlines()
+		next, err := p.Peek()
+		if err != nil {
+			return ast.Pattern{}, err
+		}
+		if next.Type == lexer.R_PAREN {
+			p.Consume()
+			return ast.NewTuplePattern(elems, utils.Encompass(tok.Value, next.Value)), nil
+		}
+		if len(elems) > 0 {
+			if next.Type != lexer.COMMA {
+				return ast.Pattern{}, p.error("expected , or ) in pattern")
+			}
+			p.Consume()
+			p.SkipNewlines()
+		}
+		elem, err := p.parsePattern()
+		if err != nil {
+			return ast.Pattern{}, err
+		}
+		elems = append(elems, elem)
+	}
+}
+
+// checkPatternNames rejects a name bound twice by a pattern, `(a, a) := t`, seen holds the names
+// bound so far. `_` binds nothing and may be repeated.
+func checkPatternNames(pattern ast.Pattern, seen map[string]bool) error {
+	if !pattern.IsTuple() {
+		if seen[pattern.Name] {
+			return utils.Error{Source: pattern.Source(), Message: "duplicate name in pattern"}
+		}
+		if pattern.Name != "_" {
+			seen[pattern.Name] = true
+		}
+		return nil
+	}
+	for _, elem := range pattern.Elems {
+		if err := checkPatternNames(elem, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assumes that the target has been parsed and the next token is the period
+func (p *Parser) parseField(target ast.Expr) (ast.Expr, error) {
+	p.Consume()
+	tok, err := p.Consume()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.Type {
+	case lexer.NUMBER_LITERAL:
+		// `t.0.1` is lexed as `t`, `.`, `0.1`
+		field := ast.Expr(target)
+		for _, index := range strings.Split(tok.Value.String(), ".") {
+			field = ast.NewFieldExpr(field, index, utils.Encompass(target.Source(), tok.Value))
+		}
+		return field, nil
+	case lexer.IDENTIFIER, lexer.USER_DEFINED, lexer.GENERIC:
+		// capitalized fields are enum variants, `Color.Black`
+		return ast.NewFieldExpr(target, tok.Value.String(), utils.Encompass(target.Source(), tok.Value)), nil
+	}
+	p.pos--
+	return nil, p.error("expected a field name after .")
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"com.loop.anonx3247/env"
+	"com.loop.anonx3247/lexer"
+	"com.loop.anonx3247/utils"
+)
+
+// parseType parses a type annotation such as `u8`, `str`, `List<i32>`, `Person`, `str?` or
+// `fn(i32): bool`
+func (p *Parser) parseType() (env.Type, error) {
+	t, err := p.parseRequiredType()
+	if err != nil {
+		return nil, err
+	}
+	// `x : i32?= 5` is lexed with `?=`
+	p.splitToken(lexer.OPTIONAL_ASSIGN, lexer.OPTIONAL, lexer.ASSIGN)
+	if _, err := p.TryConsume(lexer.OPTIONAL); err == nil {
+		return env.OptionalType{Elem: t}, nil
+	}
+	return t, nil
+}
+
+// parseRequiredType parses a type annotation that is not optional
+func (p *Parser) parseRequiredType() (env.Type, error) {
+	tok, err := p.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if tok.Type == lexer.USER_DEFINED && tok.Value.String() == "List" {
+		p.Consume()
+		elem, err := p.parseTypeArgument()
+		if err != nil {
+			return nil, err
+		}
+		return env.ListType{Elem: elem}, nil
+	}
+	if tok.Type == lexer.L_PAREN {
+		return p.parseTupleType()
+	}
+	if tok.Type == lexer.FN {
+		return p.parseFunctionType()
+	}
+	if tok.Type == lexer.USER_DEFINED || tok.Type == lexer.GENERIC {
+		// user types are declared at runtime, so they are referenced by name
+		p.Consume()
+		return env.NamedType{TypeName: tok.Value.String()}, nil
+	}
+	if tok.Type == lexer.IDENTIFIER && p.pos+3 <= len(p.tokens) && p.tokens[p.pos+1].Type == lexer.PERIOD {
+		next := p.PeekTokens(3)
+		// `shapes.Square` is a type declared by the imported module shapes
+		if next[2].Type != lexer.USER_DEFINED && next[2].Type != lexer.GENERIC {
+			p.pos += 2
+			return nil, p.error("expected a type of module " + tok.Value.String())
+		}
+		p.ConsumeTokens(3)
+		return env.NamedType{TypeName: next[2].Value.String(), Module: tok.Value.String()}, nil
+	}
+	if !lexer.S_TYPE.Matches(tok.Type) && tok.Type != lexer.IDENTIFIER {
+		return nil, p.error("expected a type")
+	}
+	t, ok := env.BaseTypeFromName(tok.Value.String())
+	if !ok {
+		return nil, p.error("unknown type")
+	}
+	p.Consume()
+	return t, nil
+}
+
+// parseTupleType parses `(type, type, ...)`
+func (p *Parser) parseTupleType() (env.Type, error) {
+	p.Consume()
+	elems := []env.Type{}
+	for {
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_PAREN {
+			p.Consume()
+			return env.TupleType{Elems: elems}, nil
+		}
+		if len(elems) > 0 {
+			if tok.Type != lexer.COMMA {
+				return nil, p.error("expected , or ) in tuple type")
+			}
+			p.Consume()
+		}
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+}
+
+// parseFunctionType parses `fn(type, type): type`, the return type of a function taking a
+// callback without it is not checked
+func (p *Parser) parseFunctionType() (env.Type, error) {
+	p.Consume()
+	if _, err := p.TryConsume(lexer.L_PAREN); err != nil {
+		return nil, p.error("expected ( and parameter types")
+	}
+	params := []env.Type{}
+	for {
+		tok, err := p.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == lexer.R_PAREN {
+			p.Consume()
+			break
+		}
+		if len(params) > 0 {
+			if tok.Type != lexer.COMMA {
+				return nil, p.error("expected , or ) in function type")
+			}
+			p.Consume()
+		}
+		param, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+	}
+	t := env.FunctionType{Params: params}
+	if _, err := p.TryConsume(lexer.COLON); err == nil {
+		ret, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		t.Return = ret
+	}
+	return t, nil
+}
+
+// parseTypeArgument parses `<type>`
+func (p *Parser) parseTypeArgument() (env.Type, error) {
+	_, err := p.TryConsume(lexer.LESS_THAN)
+	if err != nil {
+		return nil, p.error("expected < and a type argument")
+	}
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	// `List<List<u8>>` is closed by `>>`
+	p.splitToken(lexer.BITWISE_RIGHT_SHIFT, lexer.GREATER_THAN, lexer.GREATER_THAN)
+	_, err = p.TryConsume(lexer.GREATER_THAN)
+	if err != nil {
+		return nil, p.error("expected > after type argument")
+	}
+	return t, nil
+}
+
+// splitToken splits the next token in two when it has type joined, its first character becomes a
+// token of type first and the rest a token of type second
+func (p *Parser) splitToken(joined, first, second lexer.TokenType) {
+	tok, err := p.Peek()
+	if err != nil || tok.Type != joined {
+		return
+	}
+	head := lexer.Token{Type: first, Value: utils.String{Ptr: tok.Value.Ptr, Start: tok.Value.Start, Length: 1}}
+	tail := lexer.Token{Type: second, Value: utils.String{Ptr: tok.Value.Ptr, Start: tok.Value.Start + 1, Length: tok.Value.Length - 1}}
+	tokens := append(lexer.TokenList{}, p.tokens[:p.pos]...)
+	tokens = append(tokens, head, tail)
+	p.tokens = append(tokens, p.tokens[p.pos+1:]...)
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Error struct {
 	Source  String
@@ -10,3 +13,14 @@ type Error struct {
 func (e Error) Error() string {
 	return fmt.Sprintf("error: %s\n%s", e.Message, e.Source.ShowPosition())
 }
+
+// Errors collects every error found by a pass that does not stop at the first one
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
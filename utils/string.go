@@ -13,6 +13,20 @@ type String struct {
 	Length int
 }
 
+// files names the source texts that were read from a file other than the main one of the program,
+// by the text their Strings point to
+var files = map[*string]string{}
+
+// SetFile records that text was read from file, the positions of its Strings then show the file
+func SetFile(text *string, file string) {
+	files[text] = file
+}
+
+// File returns the file s was read from, or "" for the main file of the program
+func (s String) File() string {
+	return files[s.Ptr]
+}
+
 func StringFrom(s string, start int, length int) String {
 	return String{
 		Ptr:    &s,
@@ -22,16 +36,23 @@ func StringFrom(s string, start int, length int) String {
 }
 
 func Encompass(strings ...String) String {
+	// strings without a backing source (e.g. synthesized values) are ignored
+	var ptr *string
 	minStart := 0
 	maxEnd := 0
-	// first make sure they all have the same pointer
-	ptr := strings[0].Ptr
 	for _, s := range strings {
+		if s.Ptr == nil {
+			continue
+		}
+		if ptr == nil {
+			ptr = s.Ptr
+			minStart = s.Start
+			maxEnd = s.Start + s.Length
+		}
+		// make sure they all have the same pointer
 		if s.Ptr != ptr {
 			panic("all strings must have the same pointer")
 		}
-	}
-	for _, s := range strings {
 		if s.Start < minStart {
 			minStart = s.Start
 		}
@@ -39,7 +60,10 @@ func Encompass(strings ...String) String {
 			maxEnd = s.Start + s.Length
 		}
 	}
-	return StringFrom(*ptr, minStart, maxEnd-minStart)
+	if ptr == nil {
+		return String{}
+	}
+	return String{Ptr: ptr, Start: minStart, Length: maxEnd - minStart}
 }
 
 func (a String) Equal(b String) bool {
@@ -47,14 +71,24 @@ func (a String) Equal(b String) bool {
 }
 
 func (s String) String() string {
+	if s.Ptr == nil {
+		return ""
+	}
 	return string(*s.Ptr)[s.Start : s.Start+s.Length]
 }
 
 func (s String) ShowPosition() (output string) {
+	if s.Ptr == nil {
+		return ""
+	}
 	lines := strings.Split(*s.Ptr, "\n")
 
 	line, column := s.GetLineAndColumn()
 
+	if file := s.File(); file != "" {
+		output += fmt.Sprintf("%s:%d:%d\n", file, line, column)
+	}
+
 	if line > 1 {
 		output += "...\n"
 	}
@@ -63,10 +97,17 @@ func (s String) ShowPosition() (output string) {
 		output += fmt.Sprintf("%d:%s\n", line-1, lines[line-2])
 	}
 
-	output += fmt.Sprintf("%d:%s\n", line, lines[line-1])
+	prefix := fmt.Sprintf("%d:", line)
+	output += prefix + lines[line-1] + "\n"
 
-	for i := 0; i < column; i++ {
-		output += " "
+	// tabs are kept so that the caret lines up whatever their width
+	output += strings.Repeat(" ", len(prefix))
+	for _, c := range []rune(lines[line-1])[:column-1] {
+		if c == '\t' {
+			output += "\t"
+		} else {
+			output += " "
+		}
 	}
 
 	output += "^^^\n"
@@ -82,11 +123,13 @@ func (s String) ShowPosition() (output string) {
 	return
 }
 
+// GetLineAndColumn returns the line and column where s starts, both from 1. Columns count
+// characters rather than bytes so that they hold for non-ASCII source.
 func (s String) GetLineAndColumn() (int, int) {
 	line := 1
 	column := 1
-	for i := 0; i < s.Start; i++ {
-		if (*s.Ptr)[i] == '\n' {
+	for _, c := range (*s.Ptr)[:s.Start] {
+		if c == '\n' {
 			line++
 			column = 1
 		} else {